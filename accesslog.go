@@ -0,0 +1,112 @@
+package sse
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// ConnectionLog is the access log record of the finished stream connection.
+type ConnectionLog struct {
+	Client   ClientInfo       // client of the connection
+	Started  time.Time        // time the request was received
+	Duration time.Duration    // time the connection was open
+	Events   int              // events written, the initial ones included
+	Bytes    int64            // bytes written before the compression
+	Reason   DisconnectReason // why the connection is closed
+	Err      error            // write error for the DisconnectError reason
+}
+
+// DisconnectReason is the reason the stream connection is closed.
+type DisconnectReason int
+
+const (
+	// DisconnectClient means the client closed the connection.
+	DisconnectClient DisconnectReason = iota
+	// DisconnectServer means the server closed the connection: Close, the
+	// slow client policy, the connections limit or the drain timeout.
+	DisconnectServer
+	// DisconnectDrained means the connection is closed by Shutdown after the
+	// queued events are delivered.
+	DisconnectDrained
+	// DisconnectError means writing to the connection failed.
+	DisconnectError
+)
+
+// String returns the name of the reason for the logs.
+func (r DisconnectReason) String() string {
+	switch r {
+	case DisconnectClient:
+		return "client"
+	case DisconnectServer:
+		return "server"
+	case DisconnectDrained:
+		return "drained"
+	case DisconnectError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// WithAccessLog sets the function called with the record of every finished
+// stream connection, like the access log of the HTTP server, but with the
+// duration of the stream, the events and the bytes written and the reason of
+// the disconnect. The requests rejected before the stream is open are not
+// logged: the access log of the HTTP server has them.
+//
+//	sse.WithAccessLog(func(l sse.ConnectionLog) {
+//		log.Printf("%s %s %v events=%d bytes=%d reason=%v",
+//			l.Client.RemoteAddr, l.Client.ID, l.Duration, l.Events, l.Bytes, l.Reason)
+//	})
+func WithAccessLog(fn func(ConnectionLog)) Option {
+	return func(s *Server) {
+		s.accessLog = fn
+	}
+}
+
+// logAccess calls the access log function with the record of the finished
+// connection.
+func (s *Server) logAccess(r *http.Request, c *client, started time.Time, events int, bytes int64, err error) {
+	record := ConnectionLog{
+		Client:   s.current(c),
+		Started:  started,
+		Duration: time.Since(started),
+		Events:   events,
+		Bytes:    bytes,
+		Err:      err,
+	}
+	switch {
+	case err != nil:
+		record.Reason = DisconnectError
+	case r.Context().Err() != nil:
+		record.Reason = DisconnectClient
+	case closed(c.draining):
+		record.Reason = DisconnectDrained
+	default:
+		record.Reason = DisconnectServer
+	}
+	s.accessLog(record)
+}
+
+// closed reports whether the channel is closed.
+func closed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// countingWriter counts the bytes written.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,74 @@
+package sse
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAccessLog(t *testing.T) {
+	logs := make(chan ConnectionLog, 1)
+	s := New(WithBufferSize(4), WithAccessLog(func(l ConnectionLog) { logs <- l }))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	s.Send(Event{Data: "hi"})
+	if msg := readMessage(t, r); msg != "data: hi\n" {
+		t.Errorf("event %q", msg)
+	}
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case l := <-logs:
+		if l.Reason != DisconnectDrained || l.Err != nil {
+			t.Errorf("reason %v, error %v", l.Reason, l.Err)
+		}
+		if l.Events != 1 || l.Bytes != int64(len("data: hi\n\n")) {
+			t.Errorf("%d events, %d bytes", l.Events, l.Bytes)
+		}
+		if l.Client.ID == "" || l.Duration <= 0 || l.Started.IsZero() {
+			t.Errorf("record %+v", l)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("connection is not logged")
+	}
+}
+
+func TestAccessLogClose(t *testing.T) {
+	logs := make(chan ConnectionLog, 1)
+	s := New(WithAccessLog(func(l ConnectionLog) { logs <- l }))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	_ = connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	s.Close()
+	select {
+	case l := <-logs:
+		if l.Reason != DisconnectServer || l.Events != 0 {
+			t.Errorf("reason %v, %d events", l.Reason, l.Events)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("connection is not logged")
+	}
+}
+
+func TestDisconnectReason(t *testing.T) {
+	for reason, name := range map[DisconnectReason]string{
+		DisconnectClient:     "client",
+		DisconnectServer:     "server",
+		DisconnectDrained:    "drained",
+		DisconnectError:      "error",
+		DisconnectReason(-1): "unknown",
+	} {
+		if reason.String() != name {
+			t.Errorf("%d is %q", reason, reason.String())
+		}
+	}
+}
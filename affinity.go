@@ -0,0 +1,29 @@
+package sse
+
+import "net/http"
+
+// WithAffinityCookie sets the affinity cookie on the stream responses, so the
+// layer-7 load balancers route the reconnections of the browser to the same
+// instance, making the in-memory replay effective without a shared storage.
+// The cookie value identifies the instance, for example by the host name:
+//
+//	sse.WithAffinityCookie(http.Cookie{Name: "sse-node", Value: hostname, Path: "/"})
+//
+// The cookie is not set again when the request already has it.
+func WithAffinityCookie(cookie http.Cookie) Option {
+	return func(s *Server) {
+		s.affinity = &cookie
+	}
+}
+
+// setAffinity sets the affinity cookie of the response if the request does
+// not have it.
+func (s *Server) setAffinity(w http.ResponseWriter, r *http.Request) {
+	if s.affinity == nil {
+		return
+	}
+	if c, err := r.Cookie(s.affinity.Name); err == nil && c.Value == s.affinity.Value {
+		return
+	}
+	http.SetCookie(w, s.affinity)
+}
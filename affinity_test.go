@@ -0,0 +1,37 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAffinityCookie(t *testing.T) {
+	s := New(WithAffinityCookie(http.Cookie{Name: "node", Value: "a", Path: "/"}))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	for cookie, want := range map[string]string{
+		"":       "node=a; Path=/",
+		"node=b": "node=a; Path=/",
+		"node=a": "",
+	} {
+		req, err := http.NewRequest("GET", ts.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		if cookie != "" {
+			req.Header.Set("Cookie", cookie)
+		}
+		res, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if got := res.Header.Get("Set-Cookie"); got != want {
+			t.Errorf("%q: cookie %q, want %q", cookie, got, want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package sse
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// WithAuditWriter sets the writer receiving every broadcast event as one JSON
+// line with the timestamp and the number of clients it is delivered to, giving
+// the durable record of what was pushed:
+//
+//	{"time":"...","id":"1","event":"order","data":"...","delivered":42}
+//
+// The data of the events rendered per client (see SendLazy) is not logged.
+func WithAuditWriter(w io.Writer) Option {
+	return func(s *Server) {
+		s.auditLog = &auditLog{enc: json.NewEncoder(w)}
+	}
+}
+
+// auditLog writes the audit records.
+type auditLog struct {
+	enc *json.Encoder
+	mu  sync.Mutex
+}
+
+// auditRecord is the audit record of the broadcast event.
+type auditRecord struct {
+	Time      time.Time `json:"time"`
+	ID        string    `json:"id,omitempty"`
+	Event     string    `json:"event,omitempty"`
+	Data      string    `json:"data,omitempty"`
+	Channel   string    `json:"channel,omitempty"`
+	Scope     string    `json:"scope,omitempty"`
+	Trace     string    `json:"trace,omitempty"`
+	Delivered int       `json:"delivered"`
+}
+
+// audit writes the broadcast event to the audit log.
+func (s *Server) audit(e Event, delivered int) {
+	if s.auditLog == nil {
+		return
+	}
+	s.auditLog.mu.Lock()
+	defer s.auditLog.mu.Unlock()
+	_ = s.auditLog.enc.Encode(auditRecord{
+		Time:      time.Now().UTC(),
+		ID:        e.ID,
+		Event:     e.Name,
+		Data:      e.Data,
+		Channel:   e.Channel,
+		Scope:     e.Scope,
+		Trace:     e.Trace,
+		Delivered: delivered,
+	})
+}
@@ -0,0 +1,40 @@
+package sse
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuditWriter(t *testing.T) {
+	var log bytes.Buffer
+	s := New(WithAuditWriter(&log))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	go s.Send(Event{ID: "1", Name: "order", Data: "42"})
+	readMessage(t, r)
+	s.Send(Event{Name: "private", Channel: "nobody"})
+
+	dec := json.NewDecoder(&log)
+	for _, want := range []auditRecord{
+		{ID: "1", Event: "order", Data: "42", Delivered: 1},
+		{Event: "private", Channel: "nobody"},
+	} {
+		var rec auditRecord
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Time.IsZero() {
+			t.Error("no timestamp")
+		}
+		rec.Time = want.Time
+		if rec != want {
+			t.Errorf("record %+v, want %+v", rec, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package sse
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is reported to the error hook when the client is
+// disconnected by the circuit breaker.
+var ErrCircuitOpen = errors.New("sse: too many failed writes")
+
+// WithOnError sets the function called when the delivery to the client fails
+// and the client is disconnected.
+func WithOnError(fn func(err error, info ClientInfo)) Option {
+	return func(s *Server) {
+		s.onError = fn
+	}
+}
+
+// WithCircuitBreaker enables the circuit breaker on writes. A write fails if
+// it returns an error or takes longer than the slow duration. When the ratio
+// of failed writes among the last window writes to the client reaches the
+// given ratio, the client is disconnected and the error hook is called with
+// ErrCircuitOpen. When the same ratio is reached among the last window writes
+// to all clients, the server reports itself unhealthy.
+func WithCircuitBreaker(ratio float64, window int, slow time.Duration) Option {
+	return func(s *Server) {
+		if window < 1 {
+			window = 1
+		}
+		s.breaker = &breakerConfig{
+			ratio:  ratio,
+			window: window,
+			slow:   slow,
+			global: newBreaker(window),
+		}
+	}
+}
+
+// Healthy reports whether the ratio of failed writes to all clients is below
+// the circuit breaker threshold. Without the circuit breaker the server is
+// always healthy.
+func (s *Server) Healthy() bool {
+	if s.breaker == nil {
+		return true
+	}
+	s.breaker.mu.Lock()
+	defer s.breaker.mu.Unlock()
+	return !s.breaker.global.tripped(s.breaker.ratio)
+}
+
+// onWriteError reports the delivery error to the error hook.
+func (s *Server) onWriteError(err error, info ClientInfo) {
+	if s.onError != nil {
+		s.onError(err, info)
+	}
+}
+
+// breakerConfig holds the circuit breaker settings and the server-wide
+// statistics.
+type breakerConfig struct {
+	ratio  float64       // maximum ratio of failed writes
+	window int           // number of last writes to check
+	slow   time.Duration // write duration treated as timeout
+	global *breaker      // writes to all clients
+	mu     sync.Mutex
+}
+
+// record records the result of the write to the client and reports whether
+// the client breaker is tripped.
+func (b *breakerConfig) record(client *breaker, d time.Duration, err error) bool {
+	failed := err != nil || (b.slow > 0 && d > b.slow)
+	b.mu.Lock()
+	b.global.record(failed)
+	b.mu.Unlock()
+	client.record(failed)
+	return client.tripped(b.ratio)
+}
+
+// breaker tracks the results of the last writes.
+type breaker struct {
+	results []bool // ring of the last results: true for failed
+	next    int    // next position in the ring
+	count   int    // number of recorded results
+	failed  int    // number of failed results in the ring
+}
+
+// newBreaker returns a new breaker tracking window last writes.
+func newBreaker(window int) *breaker {
+	return &breaker{results: make([]bool, window)}
+}
+
+// record records the result of the write.
+func (b *breaker) record(failed bool) {
+	if b.count == len(b.results) {
+		if b.results[b.next] {
+			b.failed--
+		}
+	} else {
+		b.count++
+	}
+	b.results[b.next] = failed
+	if failed {
+		b.failed++
+	}
+	b.next = (b.next + 1) % len(b.results)
+}
+
+// tripped reports whether the ratio of failed writes reaches the given one.
+// The breaker is never tripped until the whole window is recorded.
+func (b *breaker) tripped(ratio float64) bool {
+	return b.count == len(b.results) && float64(b.failed) >= ratio*float64(b.count)
+}
@@ -0,0 +1,63 @@
+package sse
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	b := newBreaker(4)
+	for _, failed := range []bool{true, true, true} {
+		b.record(failed)
+	}
+	if b.tripped(0.5) {
+		t.Error("tripped before the window is full")
+	}
+	b.record(false)
+	if !b.tripped(0.75) {
+		t.Error("not tripped with 3 of 4 failed writes")
+	}
+	b.record(false)
+	b.record(false)
+	if b.tripped(0.75) {
+		t.Error("tripped with 1 of 4 failed writes")
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	errs := make(chan error, 1)
+	s := New(
+		WithCircuitBreaker(0.5, 2, time.Nanosecond), // every write is slow
+		WithOnError(func(err error, _ ClientInfo) { errs <- err }),
+	)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	if !s.Healthy() {
+		t.Error("unhealthy without writes")
+	}
+
+	go func() {
+		s.Send(Event{Data: "one"})
+		s.Send(Event{Data: "two"})
+	}()
+	readMessage(t, r)
+	readMessage(t, r)
+
+	select {
+	case err := <-errs:
+		if err != ErrCircuitOpen {
+			t.Errorf("error %v, want %v", err, ErrCircuitOpen)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("error hook is not called")
+	}
+	waitConnected(t, s, 0)
+	if s.Healthy() {
+		t.Error("healthy with all writes failed")
+	}
+}
@@ -0,0 +1,65 @@
+package sse
+
+import "sync"
+
+// WithOnConnectedChange sets the function called with the number of connected
+// clients whenever it changes, so the application can pause the expensive
+// upstream polling when nobody is listening and resume it when someone
+// connects. The function is called in order from the separate goroutine, so it
+// may publish the events, e.g. the viewers count, without blocking the
+// connections.
+func WithOnConnectedChange(fn func(connected int)) Option {
+	return func(s *Server) {
+		s.onChange = fn
+	}
+}
+
+// changes queues the clients counts for the change hook.
+type changes struct {
+	mu      sync.Mutex
+	queue   []int
+	running bool // the hook calling goroutine is running
+}
+
+// changeClients changes the broadcast set with the server locked and queues the
+// new number of clients for the change hook.
+func (s *Server) changeClients(change func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	before := len(s.clients)
+	change()
+	after := len(s.clients)
+	if s.onChange != nil && after != before {
+		s.notifyChange(after) // queued with the server locked to keep the order
+	}
+	if after != before {
+		s.watchClients(after)
+		s.watchMemory()
+	}
+}
+
+// notifyChange queues the clients count and starts the goroutine calling the
+// change hook, if not running.
+func (s *Server) notifyChange(n int) {
+	s.changes.mu.Lock()
+	defer s.changes.mu.Unlock()
+	s.changes.queue = append(s.changes.queue, n)
+	if s.changes.running {
+		return
+	}
+	s.changes.running = true
+	go func() {
+		for {
+			s.changes.mu.Lock()
+			if len(s.changes.queue) == 0 {
+				s.changes.running = false
+				s.changes.mu.Unlock()
+				return
+			}
+			n := s.changes.queue[0]
+			s.changes.queue = s.changes.queue[1:]
+			s.changes.mu.Unlock()
+			s.onChange(n)
+		}
+	}()
+}
@@ -0,0 +1,48 @@
+package sse
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOnConnectedChange(t *testing.T) {
+	changes := make(chan int, 4)
+	s := New(WithOnConnectedChange(func(n int) { changes <- n }))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	connect(t, ts, nil)
+	connect(t, ts, nil)
+	s.Close()
+	for _, want := range []int{1, 2, 1, 0} {
+		select {
+		case n := <-changes:
+			if n != want {
+				t.Errorf("connected %d, want %d", n, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no change to %d", want)
+		}
+	}
+}
+
+func TestOnConnectedChangePublish(t *testing.T) {
+	var st *StateStream
+	s := New(WithOnConnectedChange(func(n int) {
+		st.Delta(Event{Name: "viewers", Data: fmt.Sprint(n)})
+	}))
+	st = NewStateStream(s, func() (Event, error) { return Event{Name: "state", Data: "{}"}, nil })
+	ts := httptest.NewServer(st)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil) // hangs if the hook is called under the stream lock
+	if msg := readMessage(t, r); msg != "event: state\ndata: {}\nid: 0\n" {
+		t.Errorf("snapshot %q", msg)
+	}
+	if msg := readMessage(t, r); msg != "event: viewers\ndata: 1\nid: 1\n" {
+		t.Errorf("viewers %q", msg)
+	}
+}
@@ -0,0 +1,199 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// chunk is the data of the event part sent with SendChunked.
+type chunk struct {
+	Chunk *chunkHeader `json:"chunk"`
+	Data  string       `json:"data"`
+}
+
+// chunkHeader identifies the part of the chunked event.
+type chunkHeader struct {
+	ID    string `json:"id"`              // identifier of the chunked event
+	Seq   int    `json:"seq"`             // part number from zero
+	Final bool   `json:"final,omitempty"` // the last part
+}
+
+// SendChunked sends the event with the data legitimately exceeding the size
+// cap (see WithMaxEventSize) as several events of the same name with the parts
+// of the data and the sequence and final markers:
+//
+//	{"chunk":{"id":"5f1b...","seq":0},"data":"first part"}
+//	{"chunk":{"id":"5f1b...","seq":1,"final":true},"data":"last part"}
+//
+// Each part is encoded to the frame of up to size bytes, including the JSON
+// escaping, the event fields, the envelope and the metadata comment; the size
+// cap is used if size is not positive. The event identifier is sent with the
+// final part only, so the client reconnecting in the middle of the parts does
+// not resume after them. Only the final part is stored in the event store (see
+// WithEventStore): the replayed final part alone is discarded by the
+// Reassembler. The parts are joined back by the Reassembler or by the browser
+// code. The data is split on the UTF-8 character boundaries.
+//
+// All parts are prepared before sending any of them: ErrTooLarge is returned
+// and nothing is sent if the size does not fit even the part framing.
+func (s *Server) SendChunked(e Event, size int) error {
+	if size <= 0 || (s.maxSize > 0 && size > s.maxSize) {
+		size = s.maxSize
+	}
+	id := newClientID()
+	var parts []Event
+	data := e.Data
+	for seq := 0; ; seq++ {
+		out, n, err := s.chunk(e, id, seq, data, size)
+		if err != nil {
+			return err
+		}
+		parts = append(parts, out)
+		if n == len(data) {
+			break
+		}
+		data = data[n:]
+	}
+	for _, out := range parts {
+		if _, err := s.sendTo(context.Background(), out, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunk returns the part of the chunked event with the longest prefix of the
+// data encoded to the frame of up to size bytes and the prefix length.
+func (s *Server) chunk(e Event, id string, seq int, data string, size int) (Event, int, error) {
+	part := func(n int) (Event, error) {
+		for n < len(data) && !utf8.RuneStart(data[n]) {
+			n-- // the prefix of the whole characters
+		}
+		c := chunk{Chunk: &chunkHeader{ID: id, Seq: seq, Final: n == len(data)}, Data: data[:n]}
+		b, err := json.Marshal(c)
+		if err != nil {
+			return Event{}, err
+		}
+		out := e
+		out.Data = string(b)
+		if !c.Chunk.Final {
+			out.ID = ""
+			out.QoS = QoSFireAndForget // not stored with the identifier
+		}
+		return out, nil
+	}
+	if size <= 0 {
+		out, err := part(len(data))
+		return out, len(data), err
+	}
+	// the longest prefix fits: the frame grows with the prefix
+	lo, hi := 0, len(data)
+	if hi > size {
+		hi = size
+	}
+	var (
+		found Event
+		n     int
+	)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		out, err := part(mid)
+		if err != nil {
+			return Event{}, 0, err
+		}
+		if s.frameSize(out) > size {
+			hi = mid - 1
+			continue
+		}
+		lo = mid
+		found, n = out, mid
+	}
+	for n < len(data) && n > 0 && !utf8.RuneStart(data[n]) {
+		n--
+	}
+	if n == 0 && len(data) > 0 {
+		return Event{}, 0, ErrTooLarge
+	}
+	if n == 0 { // the empty data
+		out, err := part(0)
+		if err == nil && s.frameSize(out) > size {
+			err = ErrTooLarge
+		}
+		return out, 0, err
+	}
+	return found, n, nil
+}
+
+// frameSize returns the largest size of the event frame sent to the clients
+// with the metadata comment. Unlike encode, it has no side effects: the
+// envelope is sized with the widest time and sequence number.
+func (s *Server) frameSize(e Event) int {
+	if s.envelope && e.Data != "" {
+		e = s.envelopAt(e, widestTime, math.MaxUint64)
+	}
+	return len(s.format(e)) + s.metadataSize(e)
+}
+
+// maxChunked is the maximum number of the chunked events reassembled at once.
+const maxChunked = 64
+
+// Reassembler joins the parts of the events sent with SendChunked. The parts
+// of the event missing or received out of order are discarded.
+type Reassembler struct {
+	mu      sync.Mutex
+	pending map[string]*strings.Builder // the parts received by the event id
+	next    map[string]int              // next expected part by the event id
+}
+
+// NewReassembler returns the new Reassembler.
+func NewReassembler() *Reassembler {
+	return &Reassembler{
+		pending: make(map[string]*strings.Builder),
+		next:    make(map[string]int),
+	}
+}
+
+// Add adds the received event and returns the complete one. The events which
+// are not chunked are returned as is. It reports false if the event is the
+// part waiting for the rest ones.
+func (r *Reassembler) Add(e Event) (Event, bool) {
+	var part chunk
+	if err := json.Unmarshal([]byte(e.Data), &part); err != nil || part.Chunk == nil || part.Chunk.ID == "" {
+		return e, true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := part.Chunk.ID
+	buf, ok := r.pending[id]
+	if part.Chunk.Seq == 0 {
+		if len(r.pending) >= maxChunked {
+			for id := range r.pending {
+				r.forget(id) // the parts probably lost
+				break
+			}
+		}
+		buf, ok = new(strings.Builder), true
+		r.pending[id] = buf
+	} else if !ok || r.next[id] != part.Chunk.Seq {
+		r.forget(id)
+		return Event{}, false
+	}
+	buf.WriteString(part.Data)
+	if !part.Chunk.Final {
+		r.next[id] = part.Chunk.Seq + 1
+		return Event{}, false
+	}
+	r.forget(id)
+	e.Data = buf.String()
+	return e, true
+}
+
+// forget removes the parts of the event.
+func (r *Reassembler) forget(id string) {
+	delete(r.pending, id)
+	delete(r.next, id)
+}
@@ -0,0 +1,138 @@
+package sse
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSendChunked(t *testing.T) {
+	s := New(WithMaxEventSize(128))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+
+	data := strings.Repeat("экспорт ", 30) // multibyte runes
+	errc := make(chan error, 1)
+	go func() { errc <- s.SendChunked(Event{ID: "7", Name: "export", Data: data}, 0) }()
+
+	dec := NewDecoder(r)
+	re := NewReassembler()
+	var parts int
+	for {
+		e, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts++
+		if e, ok := re.Add(e); ok {
+			if e.Data != data || e.ID != "7" || e.Name != "export" {
+				t.Errorf("reassembled %+v", e)
+			}
+			break
+		}
+		if e.ID != "" {
+			t.Errorf("part with id %q", e.ID)
+		}
+	}
+	if parts < 2 {
+		t.Errorf("%d parts", parts)
+	}
+	if err := <-errc; err != nil {
+		t.Error(err)
+	}
+
+	if e, ok := re.Add(Event{Data: `{"id":1}`}); !ok || e.Data != `{"id":1}` {
+		t.Errorf("not chunked %+v", e)
+	}
+	if _, ok := re.Add(Event{Data: `{"chunk":{"id":"x","seq":1,"final":true},"data":"lost"}`}); ok {
+		t.Error("part out of order is reassembled")
+	}
+}
+
+func TestSendChunkedFrameSize(t *testing.T) {
+	const size = 128
+	s := New(WithMaxEventSize(size))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+
+	data := strings.Repeat("\"quoted\"\n<экспорт>", 40) // escaped in JSON
+	errc := make(chan error, 1)
+	go func() { errc <- s.SendChunked(Event{ID: "7", Name: "export", Data: data}, size) }()
+	re := NewReassembler()
+	for {
+		msg := readMessage(t, r)
+		if len(msg) > size {
+			t.Fatalf("frame of %d bytes", len(msg))
+		}
+		e, err := NewDecoder(strings.NewReader(msg + "\n")).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e, ok := re.Add(e); ok {
+			if e.Data != data {
+				t.Errorf("reassembled %q", e.Data)
+			}
+			break
+		}
+	}
+	if err := <-errc; err != nil {
+		t.Error(err)
+	}
+
+	// the framing does not fit: nothing is sent
+	if err := s.SendChunked(Event{Name: strings.Repeat("x", size), Data: data}, 0); err != ErrTooLarge {
+		t.Errorf("error %v", err)
+	}
+	go s.Send(Event{Data: "next"})
+	if msg := readMessage(t, r); msg != "data: next\n" {
+		t.Errorf("message %q", msg)
+	}
+}
+
+func TestSendChunkedEnvelope(t *testing.T) {
+	const size = 400
+	store := NewMemoryStore(10)
+	var reported error
+	s := New(WithMaxEventSize(size), WithEnvelope(), WithMetadata(), WithEventStore(store),
+		WithOnError(func(err error, _ ClientInfo) { reported = err }))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+
+	data := strings.Repeat("x", 1000)
+	errc := make(chan error, 1)
+	go func() { errc <- s.SendChunked(Event{Name: "export", Data: data, QoS: QoSStore}, 0) }()
+	dec := NewDecoder(r)
+	var parts uint64
+	for {
+		e, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts++
+		env, err := ParseEnvelope(e.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if env.Meta.Seq != parts {
+			t.Errorf("part %d envelope seq %d", parts, env.Meta.Seq)
+		}
+		if strings.Contains(string(env.Data), `"final":true`) {
+			break
+		}
+	}
+	if err := <-errc; err != nil || reported != nil {
+		t.Fatal(err, reported) // the frames with the metadata fit the cap
+	}
+	if stored, _ := store.Since(""); len(stored) != 1 {
+		t.Errorf("%d of %d parts stored", len(stored), parts)
+	}
+}
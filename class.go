@@ -0,0 +1,72 @@
+package sse
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ClientClass is the delivery profile of the connections of one class, such
+// as the mobile ones, so the constrained clients get the gentler stream. The
+// zero fields keep the server defaults.
+type ClientClass struct {
+	Retry     time.Duration // reconnection delay advised on connect
+	Throttle  time.Duration // writes throttle interval (see WithThrottleParam)
+	Heartbeat time.Duration // heartbeat interval (see WithHeartbeat)
+	Flush     FlushPolicy   // flush policy (see WithFlushPolicy)
+}
+
+// WithClientClasses sets the function classifying the connections, such as
+// ClassifyUserAgent, and the delivery profiles of the classes. The class is
+// available to the hooks and the middleware as ClientInfo.Class. The retry
+// advice of the class takes precedence over the retry policy, and the
+// throttle query parameter of the connection over the throttle of the class.
+//
+//	sse.WithClientClasses(sse.ClassifyUserAgent("class"), map[string]sse.ClientClass{
+//		"mobile": {Retry: 10 * time.Second, Throttle: time.Second, Heartbeat: time.Minute},
+//		"bot":    {Retry: time.Minute, Throttle: 5 * time.Second},
+//	})
+func WithClientClasses(classify func(r *http.Request) string, classes map[string]ClientClass) Option {
+	return func(s *Server) {
+		s.classify = classify
+		s.classes = classes
+	}
+}
+
+// ClassifyUserAgent returns the function classifying the connections as
+// "bot", "mobile" or "desktop" by the User-Agent header. The query parameter
+// with the given name, if not empty, sets the class explicitly, e.g.
+// ?class=mobile for the native applications.
+func ClassifyUserAgent(param string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		if param != "" {
+			if class := r.URL.Query().Get(param); class != "" {
+				return class
+			}
+		}
+		ua := strings.ToLower(r.Header.Get("User-Agent"))
+		switch {
+		case containsAny(ua, "bot", "crawler", "spider", "curl", "wget"):
+			return "bot"
+		case containsAny(ua, "mobile", "android", "iphone", "ipad"):
+			return "mobile"
+		default:
+			return "desktop"
+		}
+	}
+}
+
+// containsAny reports whether the string contains any of the substrings.
+func containsAny(s string, substrs ...string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// class returns the delivery profile of the client.
+func (s *Server) class(info ClientInfo) ClientClass {
+	return s.classes[info.Class]
+}
@@ -0,0 +1,53 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClassifyUserAgent(t *testing.T) {
+	classify := ClassifyUserAgent("class")
+	for _, test := range []struct {
+		url, ua, want string
+	}{
+		{"/", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)", "mobile"},
+		{"/", "Mozilla/5.0 (Linux; Android 14) Mobile Safari", "mobile"},
+		{"/", "Googlebot/2.1 (+http://www.google.com/bot.html)", "bot"},
+		{"/", "curl/8.0", "bot"},
+		{"/", "Mozilla/5.0 (X11; Linux x86_64) Firefox/120.0", "desktop"},
+		{"/?class=tv", "curl/8.0", "tv"},
+	} {
+		r := httptest.NewRequest("GET", test.url, nil)
+		r.Header.Set("User-Agent", test.ua)
+		if class := classify(r); class != test.want {
+			t.Errorf("%s %q: class %q, want %q", test.url, test.ua, class, test.want)
+		}
+	}
+}
+
+func TestClientClasses(t *testing.T) {
+	s := New(WithClientClasses(ClassifyUserAgent(""), map[string]ClientClass{
+		"mobile": {Retry: 10 * time.Second, Throttle: time.Second, Heartbeat: 10 * time.Millisecond},
+	}))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, http.Header{"User-Agent": {"Mozilla/5.0 (iPhone)"}})
+	if msg := readMessage(t, r); msg != "retry: 10000\n" {
+		t.Errorf("retry advice %q", msg)
+	}
+	if msg := readMessage(t, r); msg != ":\n" {
+		t.Errorf("heartbeat %q", msg)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	if th := s.throttle(req, ClientInfo{Class: "mobile"}, systemClock{}); th == nil || th.interval != time.Second {
+		t.Errorf("mobile throttle %+v", th)
+	}
+	if th := s.throttle(req, ClientInfo{Class: "desktop"}, systemClock{}); th != nil {
+		t.Errorf("desktop throttle %+v", th)
+	}
+}
@@ -0,0 +1,334 @@
+package sse
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Client receives the events from the HTML5 Server-Sent Events stream and
+// reconnects automatically, continuing from the last received event. It uses
+// only the net/http client, so it works in the browsers too when compiled with
+// GOOS=js GOARCH=wasm: the requests are made with the Fetch API streaming the
+// response body.
+type Client struct {
+	url       string
+	client    *http.Client
+	header    http.Header
+	verifyKey []byte
+	resume    ResumeStore
+	tls       *tls.Config // TLS configuration of the transport
+	proxy     *url.URL    // proxy of the transport
+	clock     Clock       // reconnection delay and activity time source
+
+	pauseBuffer int
+	envelope    bool // events data is unwrapped from the envelope
+
+	traceContext func(context.Context, string) context.Context // consuming trace context
+
+	lastEvent    atomic.Int64 // time of the last received event, ns
+	lastActivity atomic.Int64 // time of the last received data, ns
+	reconnects   atomic.Int64 // successful reconnections
+	parseErrors  atomic.Int64 // malformed fields received
+
+	mu           sync.Mutex
+	lastID       string        // last received event identifier
+	retry        time.Duration // reconnection delay
+	err          error         // reason the stream is closed
+	paused       bool          // events are not dispatched
+	pauseChanged chan struct{} // closed when the pause is switched
+}
+
+// ClientOption configures the Client.
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the HTTP client used for connections. By default the
+// http.DefaultClient is used. The client must not have the timeout, as the
+// stream response is never completed.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// WithRequestHeader adds the header to the stream requests.
+func WithRequestHeader(key, value string) ClientOption {
+	return func(c *Client) {
+		c.header.Add(key, value)
+	}
+}
+
+// WithLastEventID sets the identifier of the last received event to continue
+// the stream from.
+func WithLastEventID(id string) ClientOption {
+	return func(c *Client) {
+		c.lastID = id
+	}
+}
+
+// WithReconnectDelay sets the initial delay before reconnection. The server
+// may change it with the retry field. The default is 3 seconds.
+func WithReconnectDelay(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.retry = d
+	}
+}
+
+// WithVerifyKey enables checking of the event signatures made by the server
+// with the same key (see WithSigningKey). The events without the valid
+// signature are skipped.
+func WithVerifyKey(key []byte) ClientOption {
+	return func(c *Client) {
+		c.verifyKey = key
+	}
+}
+
+// NewClient returns a new client of the events stream at the given URL.
+func NewClient(url string, opts ...ClientOption) *Client {
+	c := &Client{
+		url:    url,
+		client: http.DefaultClient,
+		header: make(http.Header),
+		retry:  3 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.clock = clockOrSystem(c.clock)
+	c.transport()
+	return c
+}
+
+// errNoContent is returned when the server asks the client to stop
+// reconnecting with 204 No Content response.
+var errNoContent = errors.New("sse: server stopped the stream")
+
+// maxExcerpt is the maximum size of the response body excerpt in the errors.
+const maxExcerpt = 512
+
+// ErrBadStatus is returned when the server responds with the status other
+// than 200 OK. The client does not reconnect after such response.
+type ErrBadStatus struct {
+	Code int    // response status code
+	Body string // excerpt of the response body
+}
+
+func (e *ErrBadStatus) Error() string {
+	return fmt.Sprintf("sse: unexpected response status %d %s", e.Code, http.StatusText(e.Code))
+}
+
+// Is reports whether the status is 401 Unauthorized or 403 Forbidden for the
+// ErrUnauthorized target.
+func (e *ErrBadStatus) Is(target error) bool {
+	return target == ErrUnauthorized &&
+		(e.Code == http.StatusUnauthorized || e.Code == http.StatusForbidden)
+}
+
+// ErrNotEventStream is returned when the server responds with the content type
+// other than text/event-stream. The client does not reconnect after such
+// response.
+type ErrNotEventStream struct {
+	ContentType string // response content type
+	Body        string // excerpt of the response body
+}
+
+func (e *ErrNotEventStream) Error() string {
+	return fmt.Sprintf("sse: unexpected content type %q", e.ContentType)
+}
+
+// excerpt returns the beginning of the response body and closes it.
+func excerpt(res *http.Response) string {
+	defer res.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(res.Body, maxExcerpt))
+	return string(data)
+}
+
+// Events connects to the stream and returns the channel of received events.
+// The channel is closed when the context is done or the stream can not be
+// continued; Err returns the reason then. The connection errors are retried
+// after the reconnection delay, except the first one, which is returned.
+func (c *Client) Events(ctx context.Context) (<-chan Event, error) {
+	if c.resume != nil {
+		id, err := c.resume.Load()
+		if err != nil {
+			return nil, err
+		}
+		if id != "" {
+			c.mu.Lock()
+			c.lastID = id
+			c.mu.Unlock()
+		}
+	}
+	res, err := c.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	events := make(chan Event)
+	go c.run(ctx, res, events)
+	return events, nil
+}
+
+// Err returns the reason the events channel is closed.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// connect sends the stream request and checks the response.
+func (c *Client) connect(ctx context.Context) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", mimetype)
+	req.Header.Set("Cache-Control", "no-cache")
+	c.mu.Lock()
+	if c.lastID != "" {
+		req.Header.Set("Last-Event-ID", c.lastID)
+	}
+	c.mu.Unlock()
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNoContent {
+		res.Body.Close()
+		return nil, errNoContent
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, &ErrBadStatus{Code: res.StatusCode, Body: excerpt(res)}
+	}
+	contentType := res.Header.Get("Content-Type")
+	if mediatype, _, _ := mime.ParseMediaType(contentType); mediatype != mimetype {
+		return nil, &ErrNotEventStream{ContentType: contentType, Body: excerpt(res)}
+	}
+	return res, nil
+}
+
+// run reads the events from the response and reconnects when the stream is
+// interrupted.
+func (c *Client) run(ctx context.Context, res *http.Response, events chan<- Event) {
+	defer close(events)
+	for {
+		err := c.read(ctx, res, events)
+		res.Body.Close()
+		if err != nil {
+			c.stop(err)
+			return
+		}
+
+		for {
+			c.mu.Lock()
+			retry := c.retry
+			c.mu.Unlock()
+			timer := c.clock.NewTimer(retry)
+			select {
+			case <-timer.C():
+			case <-ctx.Done():
+				timer.Stop()
+				c.stop(ctx.Err())
+				return
+			}
+
+			var err error
+			if res, err = c.connect(ctx); err == nil {
+				c.reconnects.Add(1)
+				break
+			}
+			var (
+				status *ErrBadStatus
+				stream *ErrNotEventStream
+			)
+			if ctx.Err() != nil || err == errNoContent || errors.As(err, &status) || errors.As(err, &stream) {
+				c.stop(err)
+				return
+			}
+		}
+	}
+}
+
+// received is the decoded event with the resume point after it.
+type received struct {
+	event  Event
+	lastID string
+}
+
+// read sends the events from the response to the channel until the stream or
+// the context is done. It returns the error stopping the stream.
+func (c *Client) read(ctx context.Context, res *http.Response, events chan<- Event) error {
+	in := make(chan received)
+	stop := make(chan struct{})
+	defer close(stop)
+	go c.decode(res, in, stop)
+	return c.dispatch(ctx, in, events)
+}
+
+// decode sends the events decoded from the response to the channel until the
+// stream is done or stopped. The channel is closed then.
+func (c *Client) decode(res *http.Response, in chan<- received, stop <-chan struct{}) {
+	defer close(in)
+	d := NewDecoder(activityReader{r: res.Body, c: c})
+	var (
+		bad      int    // malformed fields counted
+		verified string // last event identifier of the verified events
+	)
+	for {
+		e, err := d.Decode()
+		if n := d.Malformed(); n > bad {
+			c.parseErrors.Add(int64(n - bad))
+			bad = n
+		}
+		if err != nil {
+			return
+		}
+		c.lastEvent.Store(c.clock.Now().UnixNano())
+
+		if c.verifyKey != nil {
+			signed := e
+			signed.ID, _ = d.EventID() // the server signs the own identifier
+			if !Verify(c.verifyKey, signed, d.Field("sig")) {
+				d.lastID = verified // the tampered event does not move the resume point
+				continue
+			}
+			verified = d.LastEventID()
+		}
+
+		if c.envelope {
+			e = unwrap(e)
+		}
+
+		lastID := d.LastEventID()
+		c.mu.Lock()
+		c.lastID = lastID
+		if retry := d.Retry(); retry > 0 {
+			c.retry = retry
+		}
+		c.mu.Unlock()
+
+		select {
+		case in <- received{event: e, lastID: lastID}:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// stop records the reason the stream is closed.
+func (c *Client) stop(err error) {
+	c.mu.Lock()
+	c.err = err
+	c.mu.Unlock()
+}
@@ -0,0 +1,155 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient(t *testing.T) {
+	key := []byte("key")
+	s := New(WithSigningKey(key))
+	lastIDs := make(chan string, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastIDs <- r.Header.Get("Last-Event-ID")
+		s.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := NewClient(ts.URL, WithReconnectDelay(10*time.Millisecond), WithVerifyKey(key))
+	events, err := c.Events(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := <-lastIDs; id != "" {
+		t.Errorf("first Last-Event-ID %q", id)
+	}
+	waitConnected(t, s, 1)
+
+	go s.Send(Event{ID: "1", Name: "first", Data: "one"})
+	if e := <-events; e != (Event{ID: "1", Name: "first", Data: "one"}) {
+		t.Errorf("event %+v", e)
+	}
+
+	s.Close() // the client reconnects
+	if id := <-lastIDs; id != "1" {
+		t.Errorf("Last-Event-ID %q, want 1", id)
+	}
+	waitConnected(t, s, 1)
+	go s.Send(Event{ID: "2", Data: "two"})
+	if e := <-events; e != (Event{ID: "2", Data: "two"}) {
+		t.Errorf("event %+v", e)
+	}
+
+	cancel()
+	for range events {
+	}
+	if err := c.Err(); err != context.Canceled {
+		t.Errorf("error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestClientStatus(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+	_, err := NewClient(ts.URL).Events(context.Background())
+	var status *ErrBadStatus
+	if !errors.As(err, &status) || status.Code != http.StatusNotFound || status.Body != "404 page not found\n" {
+		t.Errorf("error %#v", err)
+	}
+	if errors.Is(err, ErrUnauthorized) {
+		t.Error("not found is unauthorized")
+	}
+}
+
+func TestClientUnauthorized(t *testing.T) {
+	s := New()
+	ts := httptest.NewServer(s.Handler(WithAuth(func(r *http.Request) bool { return false })))
+	defer ts.Close()
+	defer s.Close()
+	_, err := NewClient(ts.URL).Events(context.Background())
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("error %v, want %v", err, ErrUnauthorized)
+	}
+}
+
+func TestClientContentType(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html>login</html>")
+	}))
+	defer ts.Close()
+	_, err := NewClient(ts.URL).Events(context.Background())
+	var stream *ErrNotEventStream
+	if !errors.As(err, &stream) || stream.ContentType != "text/html" || stream.Body != "<html>login</html>" {
+		t.Errorf("error %#v", err)
+	}
+}
+
+func TestClientVerifyWithoutID(t *testing.T) {
+	key := []byte("key")
+	s := New(WithSigningKey(key))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := NewClient(ts.URL, WithVerifyKey(key)).Events(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitConnected(t, s, 1)
+	go func() {
+		s.Send(Event{ID: "1", Data: "one"})
+		s.Send(Event{Data: "two"})
+		s.Send(Event{ID: "3", Data: "three"})
+	}()
+	for _, want := range []Event{{ID: "1", Data: "one"}, {ID: "1", Data: "two"}, {ID: "3", Data: "three"}} {
+		if e := <-events; e != want {
+			t.Errorf("event %+v, want %+v", e, want)
+		}
+	}
+}
+
+func TestClientTamperedResume(t *testing.T) {
+	key := []byte("key")
+	lastIDs := make(chan string, 2)
+	var n int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastIDs <- r.Header.Get("Last-Event-ID")
+		w.Header().Set("Content-Type", "text/event-stream")
+		n++
+		if n == 1 {
+			sig := Sign(key, Event{ID: "1", Data: "one"})
+			fmt.Fprintf(w, "data: one\nsig: %s\nid: 1\n\ndata: fake\nsig: bad\nid: 9\n\n", sig)
+			fmt.Fprintf(w, "data: two\nsig: %s\n\n", Sign(key, Event{Data: "two"})) // without the identifier
+			return
+		}
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := NewClient(ts.URL, WithReconnectDelay(10*time.Millisecond), WithVerifyKey(key)).Events(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []Event{{ID: "1", Data: "one"}, {ID: "1", Data: "two"}} {
+		if e := <-events; e != want {
+			t.Errorf("event %+v, want %+v", e, want)
+		}
+	}
+	<-lastIDs
+	if id := <-lastIDs; id != "1" {
+		t.Errorf("resumed from %q, want 1", id)
+	}
+}
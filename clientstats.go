@@ -0,0 +1,67 @@
+package sse
+
+import (
+	"io"
+	"time"
+)
+
+// ClientStats is the state of the client stream, such as to alert when the
+// stream goes quiet.
+type ClientStats struct {
+	LastEventID  string    // last received event identifier
+	LastEvent    time.Time // time of the last received event or zero
+	LastActivity time.Time // time of the last received data, heartbeats included
+	Reconnects   int       // successful reconnections
+	ParseErrors  int       // malformed fields received
+}
+
+// Stats returns the state of the client stream.
+func (c *Client) Stats() ClientStats {
+	return ClientStats{
+		LastEventID:  c.LastEventID(),
+		LastEvent:    unixTime(c.lastEvent.Load()),
+		LastActivity: unixTime(c.lastActivity.Load()),
+		Reconnects:   int(c.reconnects.Load()),
+		ParseErrors:  int(c.parseErrors.Load()),
+	}
+}
+
+// LastEventID returns the identifier of the last received event, the resume
+// point of the stream.
+func (c *Client) LastEventID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastID
+}
+
+// Idle returns the time since the last data received from the stream,
+// including the heartbeat comments, or zero if nothing is received yet.
+func (c *Client) Idle() time.Duration {
+	last := c.lastActivity.Load()
+	if last == 0 {
+		return 0
+	}
+	return c.clock.Now().Sub(time.Unix(0, last))
+}
+
+// unixTime returns the time of the Unix nanoseconds or zero time.
+func unixTime(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// activityReader records the time of the data received by the client.
+type activityReader struct {
+	r io.Reader
+	c *Client
+}
+
+func (a activityReader) Read(p []byte) (int, error) {
+	n, err := a.r.Read(p)
+	if n > 0 {
+		a.c.lastActivity.Store(a.c.clock.Now().UnixNano())
+	}
+	return n, err
+}
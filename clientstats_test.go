@@ -0,0 +1,50 @@
+package sse
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientStats(t *testing.T) {
+	s := New()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := NewClient(ts.URL, WithReconnectDelay(10*time.Millisecond))
+	if idle := c.Idle(); idle != 0 {
+		t.Errorf("idle before connection %v", idle)
+	}
+	events, err := c.Events(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitConnected(t, s, 1)
+	go func() {
+		s.send("retry: soon\n", nil) // malformed
+		s.Send(Event{ID: "3", Data: "three"})
+	}()
+	<-events
+	stats := c.Stats()
+	if stats.LastEventID != "3" || c.LastEventID() != "3" || stats.LastEvent.IsZero() ||
+		stats.LastActivity.IsZero() || stats.ParseErrors != 1 {
+		t.Errorf("stats %+v", stats)
+	}
+
+	s.Close() // the client reconnects
+	waitConnected(t, s, 1)
+	go s.Comment("heartbeat")
+	for deadline := time.Now().Add(time.Second); c.Stats().Reconnects != 1; {
+		if time.Now().After(deadline) {
+			t.Fatalf("stats %+v", c.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if idle := c.Idle(); idle <= 0 || idle > time.Second {
+		t.Errorf("idle %v", idle)
+	}
+}
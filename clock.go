@@ -0,0 +1,97 @@
+package sse
+
+import "time"
+
+// Clock is the source of time for the time-driven behavior: the recurring
+// events (see Every), the events TTL, the rate limit and throttle pacing, the
+// drain timeout of Shutdown, the reconnection delay and the idle time of the
+// Client. The ssetest.Clock advances the time deterministically in the tests,
+// without the real sleeps.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+	// AfterFunc calls f in its own goroutine after the duration. The C of the
+	// returned timer is nil.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is the single event timer of the Clock.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// Ticker is the recurring timer of the Clock.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// WithClock sets the clock of the server, also used by the MemoryStore and the
+// PeerCounts of the server. The system clock is used by default.
+func WithClock(clock Clock) Option {
+	return func(s *Server) {
+		s.clock = clock
+	}
+}
+
+// WithClientClock sets the clock of the client. The system clock is used by
+// default.
+func WithClientClock(clock Clock) ClientOption {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// clocked is the time-dependent part of the server, such as the MemoryStore
+// timing the stored events, getting the clock of the server.
+type clocked interface {
+	useClock(Clock)
+}
+
+// shareClock passes the clock of the server to its time-dependent parts.
+func (s *Server) shareClock() {
+	if s.clock == nil {
+		return
+	}
+	if c, ok := s.store.(clocked); ok {
+		c.useClock(s.clock)
+	}
+	if s.peers != nil {
+		s.peers.useClock(s.clock)
+	}
+}
+
+// clockOrSystem returns the clock or the system clock if it is nil.
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return systemClock{}
+	}
+	return clock
+}
+
+// systemClock is the Clock of the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return systemTimer{time.AfterFunc(d, f)}
+}
+
+type systemTimer struct{ *time.Timer }
+
+func (t systemTimer) C() <-chan time.Time { return t.Timer.C }
+
+type systemTicker struct{ *time.Ticker }
+
+func (t systemTicker) C() <-chan time.Time { return t.Ticker.C }
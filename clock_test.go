@@ -0,0 +1,31 @@
+package sse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSystemClock(t *testing.T) {
+	clock := clockOrSystem(nil)
+	if _, ok := clock.(systemClock); !ok {
+		t.Fatalf("default clock %T", clock)
+	}
+	start := clock.Now()
+	timer := clock.NewTimer(time.Millisecond)
+	if now := <-timer.C(); now.Before(start) {
+		t.Errorf("timer fired at %v before %v", now, start)
+	}
+	ticker := clock.NewTicker(time.Millisecond)
+	<-ticker.C()
+	ticker.Stop()
+	called := make(chan struct{})
+	if clock.AfterFunc(time.Millisecond, func() { close(called) }).C() != nil {
+		t.Error("channel of the function timer")
+	}
+	<-called
+
+	s := New(WithClock(clock))
+	if s.clock != clock {
+		t.Error("clock is not set")
+	}
+}
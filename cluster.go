@@ -0,0 +1,77 @@
+package sse
+
+import (
+	"sync"
+	"time"
+)
+
+// PeerCounts collects the numbers of the clients connected to the other
+// instances of the cluster, gossiped through the distribution backend (Redis,
+// NATS): each instance publishes its own count, e.g. with WithOnConnectedChange
+// and periodically, and sets the counts received from the peers:
+//
+//	peers := sse.NewPeerCounts(time.Minute)
+//	s := sse.New(
+//		sse.WithPeerCounts(peers),
+//		sse.WithOnConnectedChange(func(n int) { bus.Publish("sse.count", node, n) }),
+//	)
+//	bus.Subscribe("sse.count", func(node string, n int) { peers.Set(node, n) })
+//
+// The counts not updated within their time to live are expired, so the
+// stopped instances are not counted.
+type PeerCounts struct {
+	ttl    time.Duration
+	mu     sync.Mutex
+	counts map[string]peerCount
+	clock  Clock // time of the updates, the system clock if nil
+}
+
+// peerCount is the gossiped count of the instance.
+type peerCount struct {
+	n       int
+	updated time.Time
+}
+
+// NewPeerCounts returns the peer counts expired after the time to live. The
+// zero ttl never expires the counts.
+func NewPeerCounts(ttl time.Duration) *PeerCounts {
+	return &PeerCounts{ttl: ttl, counts: make(map[string]peerCount)}
+}
+
+// Set sets the number of the clients connected to the instance.
+func (p *PeerCounts) Set(node string, n int) {
+	p.mu.Lock()
+	p.counts[node] = peerCount{n: n, updated: clockOrSystem(p.clock).Now()}
+	p.mu.Unlock()
+}
+
+// Total returns the number of the clients connected to the peers.
+func (p *PeerCounts) Total() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var total int
+	now := clockOrSystem(p.clock).Now()
+	for node, c := range p.counts {
+		if p.ttl > 0 && now.Sub(c.updated) > p.ttl {
+			delete(p.counts, node)
+			continue
+		}
+		total += c.n
+	}
+	return total
+}
+
+// useClock sets the clock of the server expiring the counts.
+func (p *PeerCounts) useClock(clock Clock) {
+	p.mu.Lock()
+	p.clock = clock
+	p.mu.Unlock()
+}
+
+// WithPeerCounts sets the counts of the clients connected to the other
+// instances, reported by Stats as the cluster-wide number of clients.
+func WithPeerCounts(p *PeerCounts) Option {
+	return func(s *Server) {
+		s.peers = p
+	}
+}
@@ -0,0 +1,29 @@
+package sse
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPeerCounts(t *testing.T) {
+	peers := NewPeerCounts(time.Minute)
+	s := New(WithPeerCounts(peers))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	peers.Set("b", 3)
+	peers.Set("c", 2)
+	peers.Set("b", 4)
+	if stats := s.Stats(); stats.Clients != 1 || stats.Cluster != 7 {
+		t.Errorf("stats %+v", stats)
+	}
+
+	peers.counts["c"] = peerCount{n: 2, updated: time.Now().Add(-time.Hour)}
+	if n := peers.Total(); n != 4 {
+		t.Errorf("expired total %d", n)
+	}
+}
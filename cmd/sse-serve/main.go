@@ -0,0 +1,123 @@
+// Command sse-serve reads the lines from the standard input and broadcasts
+// them as HTML5 Server-Sent Events on the HTTP endpoint until the input ends.
+//
+// Usage:
+//
+//	sse-serve [flags]
+//
+// The flags are:
+//
+//	-addr address
+//		HTTP server address (default ":8000")
+//	-path path
+//		events stream path (default "/events")
+//	-event name
+//		name of the events
+//	-ndjson
+//		read the events as JSON objects {"id", "event", "data"}, one per line
+//	-cors
+//		allow cross-origin requests from any origin
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/mdigger/sse"
+)
+
+func main() {
+	var (
+		addr   = flag.String("addr", ":8000", "HTTP server `address`")
+		path   = flag.String("path", "/events", "events stream `path`")
+		name   = flag.String("event", "", "`name` of the events")
+		ndjson = flag.Bool("ndjson", false, "read the events as JSON objects {\"id\", \"event\", \"data\"}, one per line")
+		cors   = flag.Bool("cors", false, "allow cross-origin requests from any origin")
+	)
+	flag.Parse()
+
+	// slow clients must not block reading of the input
+	s := sse.New(sse.WithBufferSize(64), sse.WithSlowClientPolicy(sse.SlowClientDrop))
+	var opts []sse.HandlerOption
+	if *cors {
+		opts = append(opts, sse.WithCORS("*"))
+	}
+	mux := http.NewServeMux()
+	mux.Handle(*path, s.Handler(opts...))
+	srv := &http.Server{Addr: *addr, Handler: mux}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+	log.Printf("serving events on http://%s%s", *addr, *path)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() { errc <- broadcast(s, os.Stdin, *name, *ndjson) }()
+	select {
+	case err := <-errc:
+		if err != nil {
+			log.Print(err)
+		}
+	case <-ctx.Done():
+	}
+
+	// the events queued for the clients are delivered before the exit
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		log.Print("events not delivered: ", err)
+	}
+	_ = srv.Shutdown(ctx)
+}
+
+// drainTimeout limits the delivery of the queued events on exit.
+const drainTimeout = 5 * time.Second
+
+// message is the event read from NDJSON input.
+type message struct {
+	ID    string          `json:"id"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// broadcast sends the lines read from r as events until the input ends.
+func broadcast(s *sse.Server, r io.Reader, name string, ndjson bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		e := sse.Event{Name: name, Data: scanner.Text()}
+		if ndjson {
+			var msg message
+			if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+				return fmt.Errorf("line %d: %w", n, err)
+			}
+			e = sse.Event{ID: msg.ID, Name: msg.Event, Data: string(msg.Data)}
+			if e.Name == "" {
+				e.Name = name
+			}
+			// the string data is sent as is, other values as JSON
+			var text string
+			if err := json.Unmarshal(msg.Data, &text); err == nil {
+				e.Data = text
+			}
+		}
+		s.Send(e)
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdigger/sse"
+)
+
+func TestBroadcast(t *testing.T) {
+	s := sse.New()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := sse.NewClient(ts.URL).Events(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(time.Second); s.Connected() == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("client is not connected")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	const input = `{"id":"1","event":"log","data":"started"}` + "\n" +
+		`{"data":{"level":"info"}}` + "\n"
+	go func() {
+		if err := broadcast(s, strings.NewReader(input), "default", true); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	for _, want := range []sse.Event{
+		{ID: "1", Name: "log", Data: "started"},
+		{ID: "1", Name: "default", Data: `{"level":"info"}`}, // the last identifier is kept
+	} {
+		if e := <-events; e != want {
+			t.Errorf("event %+v, want %+v", e, want)
+		}
+	}
+
+	if err := broadcast(s, strings.NewReader("not json\n"), "", true); err == nil {
+		t.Error("invalid JSON accepted")
+	}
+}
@@ -0,0 +1,141 @@
+// Command sse-tail connects to the HTML5 Server-Sent Events stream and prints
+// the received events.
+//
+// Usage:
+//
+//	sse-tail [flags] url
+//
+// The flags are:
+//
+//	-header "Name: value"
+//		add the request header; may be repeated
+//	-last-event-id id
+//		continue the stream after the event with the given identifier
+//	-event name
+//		print only the events with the given name; may be repeated
+//	-json
+//		print the events as JSON objects, one per line
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/mdigger/sse"
+)
+
+// multiFlag is the repeatable string flag.
+type multiFlag []string
+
+func (f *multiFlag) String() string     { return strings.Join(*f, ", ") }
+func (f *multiFlag) Set(v string) error { *f = append(*f, v); return nil }
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintln(os.Stderr, "sse-tail:", err)
+		os.Exit(1)
+	}
+}
+
+// run connects to the stream and prints the events until the context is done.
+func run(ctx context.Context, args []string, w io.Writer) error {
+	var (
+		headers, names multiFlag
+		lastID         string
+		asJSON         bool
+	)
+	flags := flag.NewFlagSet("sse-tail", flag.ContinueOnError)
+	flags.Var(&headers, "header", "add the request `header` \"Name: value\"; may be repeated")
+	flags.StringVar(&lastID, "last-event-id", "", "continue the stream after the event with the given `id`")
+	flags.Var(&names, "event", "print only the events with the given `name`; may be repeated")
+	flags.BoolVar(&asJSON, "json", false, "print the events as JSON objects, one per line")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: sse-tail [flags] url")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("url is required")
+	}
+
+	opts := []sse.ClientOption{sse.WithLastEventID(lastID)}
+	for _, header := range headers {
+		i := strings.IndexByte(header, ':')
+		if i < 0 {
+			return fmt.Errorf("invalid header %q", header)
+		}
+		name, value := strings.TrimSpace(header[:i]), strings.TrimSpace(header[i+1:])
+		opts = append(opts, sse.WithRequestHeader(name, value))
+	}
+
+	client := sse.NewClient(flags.Arg(0), opts...)
+	events, err := client.Events(ctx)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	for e := range events {
+		if !match(names, e.Name) {
+			continue
+		}
+		if asJSON {
+			err = enc.Encode(struct {
+				ID    string `json:"id,omitempty"`
+				Event string `json:"event,omitempty"`
+				Data  string `json:"data"`
+			}{e.ID, e.Name, e.Data})
+		} else {
+			err = printRaw(w, e)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return client.Err()
+}
+
+// match reports whether the event name is in the list or the list is empty.
+// The events without the name are of the "message" type.
+func match(names []string, name string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	if name == "" {
+		name = "message"
+	}
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// printRaw prints the event in the text stream format.
+func printRaw(w io.Writer, e sse.Event) error {
+	var b strings.Builder
+	if e.Name != "" {
+		fmt.Fprintln(&b, "event:", e.Name)
+	}
+	for _, line := range strings.Split(e.Data, "\n") {
+		fmt.Fprintln(&b, "data:", line)
+	}
+	if e.ID != "" {
+		fmt.Fprintln(&b, "id:", e.ID)
+	}
+	b.WriteByte('\n')
+	_, err := io.WriteString(w, b.String())
+	return err
+}
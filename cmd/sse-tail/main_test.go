@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdigger/sse"
+)
+
+// syncBuffer passes the written lines to the test.
+type syncBuffer struct {
+	lines chan string
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lines <- string(p)
+	return len(p), nil
+}
+
+func TestRun(t *testing.T) {
+	s := sse.New()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get("Last-Event-ID") != "41" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		s.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := &syncBuffer{lines: make(chan string, 10)}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- run(ctx, []string{
+			"-header", "Authorization: Bearer token",
+			"-last-event-id", "41",
+			"-event", "order",
+			"-json",
+			ts.URL,
+		}, out)
+	}()
+
+	for deadline := time.Now().Add(time.Second); s.Connected() == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("client is not connected")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	go func() {
+		s.Send(sse.Event{ID: "42", Name: "ping", Data: "skipped"})
+		s.Send(sse.Event{ID: "43", Name: "order", Data: "{\"id\":1}"})
+	}()
+	want := `{"id":"43","event":"order","data":"{\"id\":1}"}` + "\n"
+	if got := <-out.lines; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	cancel()
+	if err := <-errc; err != context.Canceled {
+		t.Errorf("error %v", err)
+	}
+}
+
+func TestPrintRaw(t *testing.T) {
+	var b strings.Builder
+	if err := printRaw(&b, sse.Event{ID: "1", Name: "multi", Data: "a\nb"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "event: multi\ndata: a\ndata: b\nid: 1\n\n"; b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,124 @@
+package sse
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WithCompression enables the gzip compression of the streams for the clients
+// accepting it (Accept-Encoding). The compressed stream is flushed after each
+// event, so the events still arrive in real time.
+func WithCompression() Option {
+	return WithCompressor("gzip", func(w io.Writer) Compressor {
+		return gzip.NewWriter(w)
+	})
+}
+
+// Compressor is the writer compressing the stream. Flush writes the pending
+// data to the underlying writer and is called after each event.
+type Compressor interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// WithCompressor adds the compression of the streams with the encoding, such
+// as "zstd" or "br", for the clients accepting it. The encoding is chosen by
+// the Accept-Encoding priority of the client, the encodings added first are
+// preferred with the equal priority. For example, with the zstd package:
+//
+//	sse.WithCompressor("zstd", func(w io.Writer) sse.Compressor {
+//		enc, _ := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedFastest))
+//		return enc
+//	})
+func WithCompressor(encoding string, fn func(w io.Writer) Compressor) Option {
+	return func(s *Server) {
+		s.encoders = append(s.encoders, encoder{strings.ToLower(encoding), fn})
+	}
+}
+
+// WithCompressionOptOut sets the function reporting whether the compression
+// is disabled for the connection, even if the client accepts it. It is the
+// escape hatch for the clients behind the proxies corrupting the compressed
+// streams, for example by the query parameter:
+//
+//	sse.WithCompressionOptOut(func(r *http.Request) bool {
+//		return r.URL.Query().Get("compress") == "off"
+//	})
+func WithCompressionOptOut(fn func(r *http.Request) bool) Option {
+	return func(s *Server) {
+		s.noCompress = fn
+	}
+}
+
+// encoder is the compression of the streams with the encoding.
+type encoder struct {
+	name string
+	new  func(w io.Writer) Compressor
+}
+
+// compressor negotiates the encoding of the stream and returns the writer
+// compressing it, or nil if the stream is sent as is.
+func (s *Server) compressor(w http.ResponseWriter, r *http.Request) Compressor {
+	if len(s.encoders) == 0 {
+		return nil
+	}
+	w.Header().Add("Vary", "Accept-Encoding")
+	if s.noCompress != nil && s.noCompress(r) {
+		return nil
+	}
+	names := make([]string, len(s.encoders))
+	for i, enc := range s.encoders {
+		names[i] = enc.name
+	}
+	name := negotiateEncoding(r.Header.Get("Accept-Encoding"), names...)
+	for _, enc := range s.encoders {
+		if enc.name == name {
+			w.Header().Set("Content-Encoding", name)
+			return enc.new(w)
+		}
+	}
+	return nil
+}
+
+// negotiateEncoding returns the supported encoding with the highest priority
+// in the Accept-Encoding header or empty string if none is acceptable. The
+// encodings listed first are preferred with the equal priority.
+func negotiateEncoding(header string, supported ...string) string {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		qualities[name] = q
+	}
+
+	var (
+		best  string
+		bestQ float64
+		star  = qualities["*"]
+	)
+	for _, name := range supported {
+		q, ok := qualities[name]
+		if !ok {
+			q = star
+		}
+		if q > bestQ {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,126 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompression(t *testing.T) {
+	s := New(WithCompression())
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	req, err := http.NewRequest("GET", ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Accept-Encoding", "gzip")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if enc := res.Header.Get("Content-Encoding"); enc != "gzip" {
+		t.Fatalf("content encoding %q", enc)
+	}
+	gz, err := gzip.NewReader(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitConnected(t, s, 1)
+
+	r := bufio.NewReader(gz)
+	for _, data := range []string{"first", "second"} {
+		go s.Send(Event{Data: data})
+		if msg := readMessage(t, r); msg != "data: "+data+"\n" {
+			t.Errorf("message %q", msg)
+		}
+	}
+
+	// not accepted by the client
+	connect(t, ts, http.Header{"Accept-Encoding": {"gzip;q=0"}})
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	for _, tc := range []struct{ header, want string }{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate, GZIP;q=0.5", "gzip"},
+		{"gzip;q=0", ""},
+		{"*", "br"},
+		{"br;q=0.5, gzip", "gzip"},
+		{"br, gzip", "br"},
+		{"*;q=0.1, br;q=0", "gzip"},
+	} {
+		if got := negotiateEncoding(tc.header, "br", "gzip"); got != tc.want {
+			t.Errorf("%q: %q, want %q", tc.header, got, tc.want)
+		}
+	}
+}
+
+// upperCompressor is the test "compression" upper-casing the stream.
+type upperCompressor struct {
+	w io.Writer
+}
+
+func (c *upperCompressor) Write(p []byte) (int, error) {
+	return c.w.Write(bytes.ToUpper(p))
+}
+
+func (c *upperCompressor) Flush() error { return nil }
+func (c *upperCompressor) Close() error { return nil }
+
+func TestCompressor(t *testing.T) {
+	s := New(
+		WithCompressor("upper", func(w io.Writer) Compressor {
+			return &upperCompressor{w: w}
+		}),
+		WithCompression(),
+	)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, http.Header{"Accept-Encoding": {"gzip;q=0.5, upper"}})
+	waitConnected(t, s, 1)
+	go s.Send(Event{Data: "hello"})
+	if msg := readMessage(t, r); msg != "DATA: HELLO\n" {
+		t.Errorf("message %q", msg)
+	}
+}
+
+func TestCompressionOptOut(t *testing.T) {
+	s := New(
+		WithCompression(),
+		WithCompressionOptOut(func(r *http.Request) bool {
+			return r.URL.Query().Get("compress") == "off"
+		}),
+	)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	for query, want := range map[string]string{"": "gzip", "?compress=off": ""} {
+		req, err := http.NewRequest("GET", ts.URL+query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("Accept-Encoding", "gzip")
+		res, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if enc := res.Header.Get("Content-Encoding"); enc != want {
+			t.Errorf("%q: content encoding %q, want %q", query, enc, want)
+		}
+	}
+}
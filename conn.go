@@ -0,0 +1,66 @@
+package sse
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// connKey is the context key of the network connection.
+type connKey struct{}
+
+// ConnContext stores the network connection in the context of its requests.
+// It is the http.Server ConnContext hook, giving the streams access to the
+// connection for WithConnTuning:
+//
+//	srv := &http.Server{Handler: s, ConnContext: sse.ConnContext}
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, c)
+}
+
+// Conn returns the network connection of the request stored by ConnContext.
+func Conn(r *http.Request) (net.Conn, bool) {
+	c, ok := r.Context().Value(connKey{}).(net.Conn)
+	return c, ok
+}
+
+// WithConnTuning sets the function tuning the network connection of the
+// stream, such as TCPTuning, before the events are sent. Only the connections
+// of the streams are tuned, leaving the other requests of the http.Server
+// intact. It requires ConnContext hook of the http.Server.
+func WithConnTuning(fn func(c net.Conn)) Option {
+	return func(s *Server) {
+		s.tuneConn = fn
+	}
+}
+
+// TCPTuning returns the connection tuning disabling the Nagle algorithm,
+// which noticeably delays the small event frames, and setting the TCP
+// keep-alive period, if not zero. The TLS connections are tuned too.
+func TCPTuning(keepAlive time.Duration) func(c net.Conn) {
+	return func(c net.Conn) {
+		if tc, ok := c.(interface{ NetConn() net.Conn }); ok {
+			c = tc.NetConn() // TLS connection
+		}
+		tcp, ok := c.(*net.TCPConn)
+		if !ok {
+			return
+		}
+		_ = tcp.SetNoDelay(true)
+		if keepAlive > 0 {
+			_ = tcp.SetKeepAlive(true)
+			_ = tcp.SetKeepAlivePeriod(keepAlive)
+		}
+	}
+}
+
+// tune tunes the network connection of the stream.
+func (s *Server) tune(r *http.Request) {
+	if s.tuneConn == nil {
+		return
+	}
+	if c, ok := Conn(r); ok {
+		s.tuneConn(c)
+	}
+}
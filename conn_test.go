@@ -0,0 +1,32 @@
+package sse
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConnTuning(t *testing.T) {
+	tuned := make(chan net.Conn, 1)
+	tcp := TCPTuning(time.Minute)
+	s := New(WithConnTuning(func(c net.Conn) {
+		tcp(c)
+		tuned <- c
+	}))
+	ts := httptest.NewUnstartedServer(s)
+	ts.Config.ConnContext = ConnContext
+	ts.Start()
+	defer ts.Close()
+	defer s.Close()
+
+	connect(t, ts, nil)
+	select {
+	case c := <-tuned:
+		if _, ok := c.(*net.TCPConn); !ok {
+			t.Errorf("connection %T", c)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("connection is not tuned")
+	}
+}
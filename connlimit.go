@@ -0,0 +1,72 @@
+package sse
+
+import "errors"
+
+// errConnLimit is returned when the client has the maximum number of the
+// connections and the new one is rejected.
+var errConnLimit = errors.New("sse: too many connections of the client")
+
+// ConnLimitPolicy is the action when the client with the maximum number of
+// the connections connects again.
+type ConnLimitPolicy int
+
+const (
+	// RejectNewest rejects the new connection with the 429 Too Many Requests
+	// status.
+	RejectNewest ConnLimitPolicy = iota
+	// DisconnectOldest disconnects the oldest connection of the client and
+	// accepts the new one.
+	DisconnectOldest
+)
+
+// WithMaxConnectionsPerClient limits the simultaneous connections with the
+// same client identity (see WithClientID), such as the browser tabs of the
+// account, so a single account does not monopolize the server. The anonymous
+// connections without the identity are not limited.
+func WithMaxConnectionsPerClient(n int, policy ConnLimitPolicy) Option {
+	return func(s *Server) {
+		s.connLimit = n
+		s.connPolicy = policy
+	}
+}
+
+// admit adds the client to the connections of its identity, disconnecting the
+// oldest one if necessary. It reports false if the connection is rejected.
+// The server must be locked.
+func (s *Server) admit(c *client) bool {
+	if s.connLimit <= 0 || s.clientID == nil || c.info.ID == "" {
+		return true
+	}
+	conns := s.conns[c.info.ID]
+	if len(conns) >= s.connLimit {
+		if s.connPolicy == RejectNewest {
+			return false
+		}
+		for _, old := range conns[:len(conns)-s.connLimit+1] {
+			old.disconnect()
+		}
+		conns = conns[len(conns)-s.connLimit+1:]
+	}
+	if s.conns == nil {
+		s.conns = make(map[string][]*client)
+	}
+	s.conns[c.info.ID] = append(conns, c)
+	return true
+}
+
+// release removes the client from the connections of its identity. The server
+// must be locked.
+func (s *Server) release(c *client) {
+	conns := s.conns[c.info.ID]
+	for i, conn := range conns {
+		if conn == c {
+			conns = append(conns[:i:i], conns[i+1:]...)
+			break
+		}
+	}
+	if len(conns) == 0 {
+		delete(s.conns, c.info.ID)
+	} else {
+		s.conns[c.info.ID] = conns
+	}
+}
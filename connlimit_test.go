@@ -0,0 +1,54 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMaxConnectionsPerClient(t *testing.T) {
+	user := func(r *http.Request) string { return r.Header.Get("X-User") }
+	alice := http.Header{"X-User": {"alice"}}
+
+	t.Run("reject", func(t *testing.T) {
+		s := New(WithClientID(user), WithMaxConnectionsPerClient(2, RejectNewest))
+		ts := httptest.NewServer(s)
+		defer ts.Close()
+		defer s.Close()
+
+		connect(t, ts, alice)
+		connect(t, ts, alice)
+		connect(t, ts, nil) // anonymous
+		waitConnected(t, s, 3)
+		req, _ := http.NewRequest("GET", ts.URL, nil)
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("X-User", "alice")
+		res, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusTooManyRequests {
+			t.Error("status", res.Status)
+		}
+		waitConnected(t, s, 3)
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+		s := New(WithClientID(user), WithMaxConnectionsPerClient(1, DisconnectOldest))
+		ts := httptest.NewServer(s)
+		defer ts.Close()
+		defer s.Close()
+
+		old := connect(t, ts, alice)
+		waitConnected(t, s, 1)
+		connect(t, ts, alice)
+		if _, err := old.ReadString('\n'); err == nil {
+			t.Error("the oldest connection is not closed")
+		}
+		waitConnected(t, s, 1)
+		if n := len(s.conns["alice"]); n != 1 {
+			t.Errorf("tracked %d connections", n)
+		}
+	})
+}
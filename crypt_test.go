@@ -0,0 +1,33 @@
+package sse
+
+import "testing"
+
+func TestEncrypt(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	const data = "{\"balance\":100}"
+
+	sealed, err := Encrypt(key, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sealed == data {
+		t.Fatal("data is not encrypted")
+	}
+	plain, err := Decrypt(key, sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain != data {
+		t.Errorf("decrypted %q, want %q", plain, data)
+	}
+
+	if _, err := Decrypt([]byte("fedcba9876543210fedcba9876543210"), sealed); err == nil {
+		t.Error("decrypted with a wrong key")
+	}
+	if _, err := Decrypt(key, "c2hvcnQ"); err == nil {
+		t.Error("decrypted too short data")
+	}
+	if _, err := Encrypt([]byte("short"), data); err == nil {
+		t.Error("encrypted with invalid key size")
+	}
+}
@@ -0,0 +1,96 @@
+package sse
+
+import (
+	"container/list"
+	"sync"
+)
+
+// maxCursors is the maximum number of the client cursors; the least recently
+// updated ones are forgotten.
+const maxCursors = 10000
+
+// WithCursors makes the server remember the last stored event (see
+// WithEventStore) written to each client identity (see WithClientID), so the
+// client reconnecting without the Last-Event-ID header, such as from the new
+// tab after the crashed one, resumes from the server cursor instead of
+// missing the events. The Last-Event-ID header sent by the client takes
+// precedence. The cursors of the least recently served clients are
+// forgotten.
+func WithCursors() Option {
+	return func(s *Server) {
+		s.cursors = new(cursors)
+	}
+}
+
+// cursors is the last stored event identifiers written to the clients by
+// the client identity.
+type cursors struct {
+	mu    sync.Mutex
+	ids   map[string]*list.Element // of *cursor
+	order list.List                // least recently updated first
+}
+
+// cursor is the last stored event identifier written to the client.
+type cursor struct {
+	client, id string
+}
+
+// set updates the cursor of the client.
+func (cs *cursors) set(client, id string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if el, ok := cs.ids[client]; ok {
+		el.Value.(*cursor).id = id
+		cs.order.MoveToBack(el)
+		return
+	}
+	if cs.ids == nil {
+		cs.ids = make(map[string]*list.Element)
+	}
+	cs.ids[client] = cs.order.PushBack(&cursor{client: client, id: id})
+	if cs.order.Len() > maxCursors {
+		oldest := cs.order.Front()
+		cs.order.Remove(oldest)
+		delete(cs.ids, oldest.Value.(*cursor).client)
+	}
+}
+
+// get returns the cursor of the client or an empty string.
+func (cs *cursors) get(client string) string {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if el, ok := cs.ids[client]; ok {
+		return el.Value.(*cursor).id
+	}
+	return ""
+}
+
+// tracksCursor reports whether the cursor of the client is tracked: the
+// cursors are enabled and the client has the stable identity.
+func (s *Server) tracksCursor() bool {
+	return s.cursors != nil && s.clientID != nil
+}
+
+// cursorOf returns the identifier of the event moving the cursor of the
+// client, which is the stored one, or an empty string.
+func (s *Server) cursorOf(e Event) string {
+	if !s.tracksCursor() || e.QoS == QoSFireAndForget || s.store == nil {
+		return ""
+	}
+	return e.ID
+}
+
+// advance moves the cursor of the client to the written event.
+func (s *Server) advance(c *client, id string) {
+	if id != "" && c.info.ID != "" {
+		s.cursors.set(c.info.ID, id)
+	}
+}
+
+// savedCursor returns the cursor of the client or an empty string.
+func (s *Server) savedCursor(c *client) string {
+	if !s.tracksCursor() || c.info.ID == "" {
+		return ""
+	}
+	return s.cursors.get(c.info.ID)
+}
@@ -0,0 +1,57 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestCursors(t *testing.T) {
+	s := New(WithCursors(), WithEventStore(NewMemoryStore(10)), WithBufferSize(4),
+		WithClientID(func(r *http.Request) string { return r.Header.Get("X-User") }))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+	alice := http.Header{"X-User": {"alice"}}
+
+	r := connect(t, ts, alice)
+	waitConnected(t, s, 1)
+	s.Send(Event{Data: "1", QoS: QoSStore})
+	s.Send(Event{Data: "live"}) // does not move the cursor
+	if msg := readMessage(t, r); msg != "data: 1\nid: 1\n" {
+		t.Errorf("first event %q", msg)
+	}
+	if msg := readMessage(t, r); msg != "data: live\n" {
+		t.Errorf("live event %q", msg)
+	}
+	s.Close() // the tab crashes
+	waitConnected(t, s, 0)
+	s.Send(Event{Data: "2", QoS: QoSStore})
+
+	// the new tab resumes without Last-Event-ID
+	r = connect(t, ts, alice)
+	if msg := readMessage(t, r); msg != "data: 2\nid: 2\n" {
+		t.Errorf("resumed event %q", msg)
+	}
+	waitConnected(t, s, 1)
+
+	// the client without the cursor is not replayed to
+	r = connect(t, ts, http.Header{"X-User": {"bob"}})
+	waitConnected(t, s, 2)
+	s.Send(Event{Data: "3"})
+	if msg := readMessage(t, r); msg != "data: 3\n" {
+		t.Errorf("bob event %q", msg)
+	}
+}
+
+func TestCursorsLimit(t *testing.T) {
+	var cs cursors
+	for i := 0; i <= maxCursors; i++ {
+		cs.set(strconv.Itoa(i), "1")
+	}
+	cs.set("1", "2")
+	if cs.get("0") != "" || cs.get("1") != "2" || len(cs.ids) != maxCursors {
+		t.Errorf("%d cursors", len(cs.ids))
+	}
+}
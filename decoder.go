@@ -0,0 +1,137 @@
+package sse
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decoder reads the events from the text/event-stream.
+type Decoder struct {
+	r      *bufio.Reader
+	lastID string            // last event identifier
+	id     string            // explicit identifier of the last event
+	hasID  bool              // the last event has the explicit identifier
+	retry  time.Duration     // last reconnection time
+	fields map[string]string // non-standard fields of the last event
+	meta   string            // metadata comment of the last event
+	start  bool              // the first line is read
+	bad    int               // malformed fields skipped
+}
+
+// NewDecoder returns a new decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode returns the next event of the stream. The comments and the events
+// without the data are skipped as the specification requires. The event
+// identifier is the last received one, even if the event had no explicit
+// identifier.
+func (d *Decoder) Decode() (Event, error) {
+	var (
+		e        Event
+		data     strings.Builder
+		received bool // data field is received
+	)
+	d.fields = nil
+	d.meta = ""
+	d.id, d.hasID = "", false
+	for {
+		line, err := d.r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line != "" {
+				err = io.ErrUnexpectedEOF // the event is not completed
+			}
+			return Event{}, err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if !d.start {
+			d.start = true
+			line = strings.TrimPrefix(line, "\ufeff") // byte order mark
+		}
+
+		if line == "" { // dispatch the event
+			if !received {
+				e = Event{}
+				d.fields = nil
+				d.meta = ""
+				d.id, d.hasID = "", false
+				continue
+			}
+			e.ID = d.lastID
+			e.Data = strings.TrimSuffix(data.String(), "\n")
+			return e, nil
+		}
+		if line[0] == ':' {
+			if meta := metaComment(line); meta != "" {
+				d.meta = meta
+			}
+			continue // comment
+		}
+
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "event":
+			e.Name = value
+		case "trace":
+			e.Trace = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			received = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				d.lastID = value
+				d.id, d.hasID = value, true
+			} else {
+				d.bad++
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 63); err == nil {
+				d.retry = time.Duration(ms) * time.Millisecond
+			} else {
+				d.bad++
+			}
+		default:
+			if d.fields == nil {
+				d.fields = make(map[string]string)
+			}
+			d.fields[field] = value
+		}
+	}
+}
+
+// LastEventID returns the last event identifier received from the stream.
+func (d *Decoder) LastEventID() string {
+	return d.lastID
+}
+
+// EventID returns the identifier field of the last decoded event and reports
+// whether the event has it. Unlike the Event.ID, it is not inherited from the
+// previous events, e.g. to verify the event signature.
+func (d *Decoder) EventID() (string, bool) {
+	return d.id, d.hasID
+}
+
+// Retry returns the last reconnection time received from the stream or zero.
+func (d *Decoder) Retry() time.Duration {
+	return d.retry
+}
+
+// Malformed returns the number of the malformed fields skipped, such as the
+// retry field with not a number.
+func (d *Decoder) Malformed() int {
+	return d.bad
+}
+
+// Field returns the value of the non-standard field of the last decoded
+// event, e.g. the "sig" signature.
+func (d *Decoder) Field(name string) string {
+	return d.fields[name]
+}
@@ -0,0 +1,87 @@
+package sse
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecoder(t *testing.T) {
+	const stream = "\ufeff: comment\n" +
+		"retry: 1500\n" +
+		"event: greeting\r\n" +
+		"data: hello\n" +
+		"data:world\n" +
+		"sig: abc\n" +
+		"id: 1\n" +
+		"\n" +
+		"event: empty\n" +
+		"\n" +
+		"data: no id\n" +
+		"\n" +
+		"data: unfinished"
+
+	d := NewDecoder(strings.NewReader(stream))
+	e, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Event{ID: "1", Name: "greeting", Data: "hello\nworld"}); e != want {
+		t.Errorf("event %+v, want %+v", e, want)
+	}
+	if sig := d.Field("sig"); sig != "abc" {
+		t.Errorf("sig %q", sig)
+	}
+	if d.Retry() != 1500*time.Millisecond {
+		t.Errorf("retry %v", d.Retry())
+	}
+
+	// the event without data is skipped, the identifier is kept
+	e, err = d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Event{ID: "1", Data: "no id"}); e != want {
+		t.Errorf("event %+v, want %+v", e, want)
+	}
+	if d.Field("sig") != "" {
+		t.Error("field of the previous event is kept")
+	}
+
+	if _, err = d.Decode(); err != io.ErrUnexpectedEOF {
+		t.Errorf("error %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestDecoderServer(t *testing.T) {
+	key := []byte("key")
+	s := New(WithSigningKey(key))
+	e := Event{ID: "7", Name: "multi", Data: "line 1\nline 2"}
+	d := NewDecoder(strings.NewReader(s.encode(e) + "\n"))
+	got, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != e {
+		t.Errorf("event %+v, want %+v", got, e)
+	}
+	if !Verify(key, got, d.Field("sig")) {
+		t.Error("signature is not valid")
+	}
+}
+
+func TestDecoderEventID(t *testing.T) {
+	d := NewDecoder(strings.NewReader("id: 1\ndata: one\n\ndata: two\n\n"))
+	for _, want := range []struct {
+		id  string
+		has bool
+	}{{"1", true}, {"", false}} {
+		if _, err := d.Decode(); err != nil {
+			t.Fatal(err)
+		}
+		if id, has := d.EventID(); id != want.id || has != want.has {
+			t.Errorf("event id %q %v, want %q %v", id, has, want.id, want.has)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package sse
+
+import (
+	"context"
+	"time"
+)
+
+// WithDrainTimeout sets the maximum time of delivering the queued events to
+// each client on Shutdown. Zero means no limit, though the context of
+// Shutdown still bounds the whole shutdown.
+func WithDrainTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.drainTimeout = d
+	}
+}
+
+// Shutdown closes the server gracefully: the events already accepted by Send
+// are written and flushed to every reachable client before its connection is
+// closed, within the per-connection drain timeout (see WithDrainTimeout). It
+// waits for the connections to close until the context is done; then the
+// remaining ones are disconnected and the context error is returned. Unlike
+// Close, nothing in flight is dropped. Switch on the maintenance mode (see
+// SetMaintenance) before to reject the new connections while draining.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	clients := make([]*client, 0, len(s.clients))
+	for c := range s.clients {
+		clients = append(clients, c)
+		c.drain()
+		if s.drainTimeout > 0 {
+			clockOrSystem(s.clock).AfterFunc(s.drainTimeout, c.disconnect)
+		}
+	}
+	if s.closing != nil {
+		close(s.closing)
+		s.closing = nil
+	}
+	s.mu.Unlock()
+
+	for _, c := range clients {
+		select {
+		case <-c.stopped:
+		case <-ctx.Done():
+			for _, c := range clients {
+				c.disconnect()
+			}
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Announce broadcasts the final event, such as the restart notice, and shuts
+// the server down (see Shutdown) once the event and the events sent before are
+// flushed to the clients, or when the wait is over; then the remaining
+// connections are closed and the context.DeadlineExceeded error is returned.
+// The zero wait does not limit the shutdown. The sending error of the event,
+// such as ErrTooLarge, is returned after the shutdown. Send the Retry with the expected
+// downtime before to make the browsers reconnect after the restart:
+//
+//	s.Retry(10 * time.Second)
+//	err := s.Announce(sse.Event{Name: "restart", Data: "reconnect in 10s"}, 5*time.Second)
+func (s *Server) Announce(e Event, wait time.Duration) error {
+	ctx := context.Background()
+	if wait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, wait)
+		defer cancel()
+	}
+	err := s.SendContext(ctx, e)
+	if err := s.Shutdown(ctx); err != nil {
+		return err
+	}
+	return err
+}
+
+// drain asks the client connection to close after the queued events are
+// delivered.
+func (c *client) drain() {
+	if c.draining != nil {
+		c.drainOnce.Do(func() { close(c.draining) })
+	}
+}
@@ -0,0 +1,97 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	s := New(WithBufferSize(100), WithThrottleParam("throttle", time.Minute))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	r := connect(t, ts, nil)
+	ts.URL += "?throttle=1m"
+	throttled := connect(t, ts, nil)
+	waitConnected(t, s, 2)
+	for i := 0; i < 10; i++ {
+		s.Send(Event{Data: fmt.Sprint(i)})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for name, r := range map[string]*bufio.Reader{"plain": r, "throttled": throttled} {
+		for i := 0; i < 10; i++ {
+			if msg, want := readMessage(t, r), fmt.Sprintf("data: %d\n", i); msg != want {
+				t.Fatalf("%s: got %q, want %q", name, msg, want)
+			}
+		}
+		if _, err := r.ReadString('\n'); err == nil {
+			t.Errorf("%s: not closed", name)
+		}
+	}
+}
+
+func TestShutdownDrainTimeout(t *testing.T) {
+	s := New(WithBufferSize(10), WithRateLimit(1, 1), WithDrainTimeout(50*time.Millisecond))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	for i := 0; i < 10; i++ {
+		s.Send(Event{Data: fmt.Sprint(i)}) // takes 10 seconds to deliver
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("drained in %v", d)
+	}
+}
+
+func TestAnnounce(t *testing.T) {
+	s := New(WithBufferSize(10))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	s.Send(Event{Data: "last"})
+	if err := s.Announce(Event{Name: "restart", Data: "reconnect in 10s"}, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"data: last\n", "event: restart\ndata: reconnect in 10s\n"} {
+		if msg := readMessage(t, r); msg != want {
+			t.Errorf("got %q, want %q", msg, want)
+		}
+	}
+	if _, err := r.ReadString('\n'); err == nil {
+		t.Error("not closed")
+	}
+}
+
+func TestAnnounceTimeout(t *testing.T) {
+	s := New(WithBufferSize(10), WithRateLimit(1, 1))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	for i := 0; i < 5; i++ {
+		s.Send(Event{Data: fmt.Sprint(i)}) // takes 5 seconds to deliver
+	}
+	if err := s.Announce(Event{Data: "bye"}, 50*time.Millisecond); err != context.DeadlineExceeded {
+		t.Errorf("announce error %v", err)
+	}
+	waitConnected(t, s, 0)
+}
@@ -0,0 +1,48 @@
+// Package echoadapter serves the HTML5 Server-Sent Events of the sse.Server
+// with the Echo framework.
+package echoadapter
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdigger/sse"
+)
+
+// contextKey is the key of the echo.Context in the request context.
+type contextKey struct{}
+
+// Handler returns the echo.HandlerFunc serving the events stream of the
+// broker, such as sse.Server. The echo.Context of the request is available to
+// the server hooks with Context.
+func Handler(s sse.Broker) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := c.Request()
+		r = r.WithContext(context.WithValue(r.Context(), contextKey{}, c))
+		s.ServeHTTP(c.Response(), r)
+		return nil
+	}
+}
+
+// Context returns the echo.Context of the request served by Handler or nil.
+func Context(r *http.Request) echo.Context {
+	c, _ := r.Context().Value(contextKey{}).(echo.Context)
+	return c
+}
+
+// Param returns the function which uses the value of the named path parameter
+// as the channel name, to be used with sse.WithChannels:
+//
+//	s := sse.New(sse.WithChannels(echoadapter.Param("room")))
+//	e.GET("/events/:room", echoadapter.Handler(s))
+func Param(name string) func(r *http.Request) []string {
+	return func(r *http.Request) []string {
+		if c := Context(r); c != nil {
+			if value := c.Param(name); value != "" {
+				return []string{value}
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,51 @@
+package echoadapter
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdigger/sse"
+)
+
+func TestHandler(t *testing.T) {
+	s := sse.New(sse.WithChannels(Param("room")))
+	e := echo.New()
+	e.GET("/events/:room", Handler(s))
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+	defer s.Close()
+
+	req, _ := http.NewRequest("GET", ts.URL+"/events/lobby", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatal(res.Status)
+	}
+	for deadline := time.Now().Add(time.Second); s.Connected() == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("client is not connected")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	go func() {
+		s.Send(sse.Event{Data: "kitchen", Channel: "kitchen"})
+		s.Send(sse.Event{Data: "lobby", Channel: "lobby"})
+	}()
+
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "data: lobby\n" {
+		t.Errorf("got %q", line)
+	}
+}
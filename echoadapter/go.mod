@@ -0,0 +1,27 @@
+module github.com/mdigger/sse/echoadapter
+
+go 1.22
+
+require (
+	github.com/labstack/echo/v4 v4.11.4
+	github.com/mdigger/sse v0.0.0
+)
+
+require (
+	github.com/labstack/gommon v0.4.2 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasttemplate v1.2.2 // indirect
+	golang.org/x/crypto v0.17.0 // indirect
+	golang.org/x/net v0.19.0 // indirect
+	golang.org/x/sys v0.15.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+)
+
+// The adapter is developed together with the package: the placeholder version
+// v0.0.0 is resolved to the parent directory, so the tree builds without the
+// published release. Require the released version of the package instead
+// before tagging the adapter, as the replacement is ignored by the modules
+// depending on it.
+replace github.com/mdigger/sse => ../
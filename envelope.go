@@ -0,0 +1,133 @@
+package sse
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Envelope is the standard wrapping of the event data sent with WithEnvelope,
+// giving the consumers the metadata of the event without parsing the stream
+// fields:
+//
+//	{"meta":{"id":"7","name":"order","ts":"2024-01-02T15:04:05.123Z","seq":42},"data":{"id":1}}
+//
+// The data that is not valid JSON is sent as the text string instead:
+//
+//	{"meta":{"ts":"2024-01-02T15:04:05.123Z","seq":43},"text":"plain text"}
+type Envelope struct {
+	Meta EnvelopeMeta    `json:"meta"`
+	Data json.RawMessage `json:"data,omitempty"` // JSON data of the event
+	Text string          `json:"text,omitempty"` // data of the event, if not JSON
+}
+
+// EnvelopeMeta is the metadata of the event in the envelope.
+type EnvelopeMeta struct {
+	ID    string    `json:"id,omitempty"`    // event identifier
+	Name  string    `json:"name,omitempty"`  // event type name
+	Time  time.Time `json:"ts"`              // time the event is encoded
+	Seq   uint64    `json:"seq"`             // increasing number of the encoded event
+	Trace string    `json:"trace,omitempty"` // trace context of the publisher
+}
+
+// WithEnvelope wraps the data of every event sent to the clients in the
+// Envelope with the metadata. The events without the data are not wrapped, as
+// the clients do not dispatch them. The Go client unwraps the data with
+// WithClientEnvelope; the other consumers parse it with ParseEnvelope.
+func WithEnvelope() Option {
+	return func(s *Server) {
+		s.envelope = true
+	}
+}
+
+// WithClientEnvelope makes the client unwrap the data of the events sent by
+// the server with WithEnvelope, restoring the identifier and the name of the
+// event from the metadata if the stream fields are empty. The data that is
+// not wrapped is delivered as is.
+func WithClientEnvelope() ClientOption {
+	return func(c *Client) {
+		c.envelope = true
+	}
+}
+
+// ParseEnvelope returns the envelope of the event data sent with WithEnvelope.
+func ParseEnvelope(data string) (Envelope, error) {
+	var env Envelope
+	err := json.Unmarshal([]byte(data), &env)
+	return env, err
+}
+
+// envelop returns the event with the data wrapped in the envelope, if
+// enabled.
+func (s *Server) envelop(e Event) Event {
+	if !s.envelope || e.Data == "" {
+		return e
+	}
+	t, seq := s.stamp()
+	return s.envelopAt(e, t, seq)
+}
+
+// stamp returns the time and the next sequence number of the envelope, zero
+// without the envelopes.
+func (s *Server) stamp() (time.Time, uint64) {
+	if !s.envelope {
+		return time.Time{}, 0
+	}
+	return clockOrSystem(s.clock).Now(), s.envelopeSeq.Add(1)
+}
+
+// encodeAt returns the event in text stream format wrapped in the envelope
+// with the given time and sequence number, so the event encoded again, such
+// as with the identifier assigned by the store, keeps them.
+func (s *Server) encodeAt(e Event, t time.Time, seq uint64) string {
+	if s.envelope && e.Data != "" {
+		e = s.envelopAt(e, t, seq)
+	}
+	return s.format(e)
+}
+
+// envelopAt returns the event with the data wrapped in the envelope with the
+// given time and sequence number.
+func (s *Server) envelopAt(e Event, t time.Time, seq uint64) Event {
+	env := Envelope{Meta: EnvelopeMeta{
+		ID:    e.ID,
+		Name:  e.Name,
+		Time:  t.UTC(),
+		Seq:   seq,
+		Trace: e.Trace,
+	}}
+	if json.Valid([]byte(e.Data)) {
+		env.Data = json.RawMessage(e.Data)
+	} else {
+		env.Text = e.Data
+	}
+	data, err := json.Marshal(env)
+	if err != nil {
+		return e
+	}
+	e.Data, e.Trace = string(data), ""
+	return e
+}
+
+// unwrap returns the event with the data unwrapped from the envelope, or the
+// event as is if the data is not wrapped.
+func unwrap(e Event) Event {
+	env, err := ParseEnvelope(e.Data)
+	if err != nil || env.Meta.Time.IsZero() {
+		return e
+	}
+	if env.Data != nil {
+		e.Data = string(env.Data)
+	} else {
+		e.Data = env.Text
+	}
+	if e.ID == "" {
+		e.ID = env.Meta.ID
+	}
+	if e.Name == "" {
+		e.Name = env.Meta.Name
+	}
+	if e.Trace == "" {
+		e.Trace = env.Meta.Trace
+	}
+	return e
+}
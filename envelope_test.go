@@ -0,0 +1,76 @@
+package sse
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEnvelope(t *testing.T) {
+	key := []byte("key")
+	s := New(WithEnvelope(), WithSigningKey(key), WithBufferSize(4))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	s.Send(Event{ID: "7", Name: "order", Data: `{"id":1}`})
+	s.Send(Event{Data: "plain text"})
+	msg := readMessage(t, r)
+	lines := strings.Split(msg, "\n")
+	if len(lines) < 2 || !strings.HasPrefix(lines[1], "data: ") {
+		t.Fatalf("message %q", msg)
+	}
+	env, err := ParseEnvelope(strings.TrimPrefix(lines[1], "data: "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.Meta.ID != "7" || env.Meta.Name != "order" || env.Meta.Seq != 1 || env.Meta.Time.IsZero() ||
+		string(env.Data) != `{"id":1}` || env.Text != "" {
+		t.Errorf("envelope %+v", env)
+	}
+	msg = readMessage(t, r)
+	data, _, _ := strings.Cut(strings.TrimPrefix(msg, "data: "), "\n")
+	env, err = ParseEnvelope(data)
+	if err != nil || env.Meta.Seq != 2 || env.Data != nil || env.Text != "plain text" {
+		t.Errorf("envelope %+v, error %v", env, err)
+	}
+}
+
+func TestClientEnvelope(t *testing.T) {
+	key := []byte("key")
+	s := New(WithEnvelope(), WithSigningKey(key))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := NewClient(ts.URL, WithClientEnvelope(), WithVerifyKey(key))
+	events, err := c.Events(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitConnected(t, s, 1)
+	for _, want := range []Event{
+		{ID: "1", Name: "order", Data: `{"id":1}`},
+		{ID: "2", Data: "plain text"},
+		{ID: "3", Data: `"quoted"`},
+	} {
+		go s.Send(want)
+		if e := <-events; e != want {
+			t.Errorf("event %+v, want %+v", e, want)
+		}
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	// the data not wrapped is kept as is
+	for _, data := range []string{"text", `{"id":1}`, `{"meta":{}}`} {
+		if e := unwrap(Event{Data: data}); e.Data != data {
+			t.Errorf("%q unwrapped to %q", data, e.Data)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package sse
+
+import (
+	"sync"
+	"time"
+)
+
+// Every sends the event returned by the function every interval d until the
+// returned stop function is called or the server is closed. The function is
+// not called while no clients are connected; it returns false to skip the
+// sending. A non-positive interval sends nothing and returns the no-op stop
+// function.
+func (s *Server) Every(d time.Duration, fn func() (Event, bool)) (stop func()) {
+	return s.every(d, func() {
+		if s.Connected() == 0 {
+			return
+		}
+		if e, ok := fn(); ok {
+			s.Send(e)
+		}
+	})
+}
+
+// every calls the function every interval d until the returned stop function
+// is called or the server is closed.
+func (s *Server) every(d time.Duration, fn func()) (stop func()) {
+	if d <= 0 {
+		return func() {}
+	}
+	s.mu.Lock()
+	if s.closing == nil {
+		s.closing = make(chan struct{})
+	}
+	closing := s.closing
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	ticker := clockOrSystem(s.clock).NewTicker(d)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C():
+				fn()
+			case <-done:
+				return
+			case <-closing:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,51 @@
+package sse
+
+import (
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEvery(t *testing.T) {
+	s := New()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	var calls int32
+	stop := s.Every(5*time.Millisecond, func() (Event, bool) {
+		n := atomic.AddInt32(&calls, 1)
+		return Event{Name: "tick"}, n%2 == 1
+	})
+	defer stop()
+	time.Sleep(20 * time.Millisecond)
+	if n := atomic.LoadInt32(&calls); n != 0 {
+		t.Errorf("called %d times without clients", n)
+	}
+
+	r := connect(t, ts, nil)
+	for i := 0; i < 2; i++ {
+		if msg := readMessage(t, r); msg != "event: tick\n" {
+			t.Errorf("tick %q", msg)
+		}
+	}
+
+	s.Close() // stops the recurring events
+	time.Sleep(10 * time.Millisecond)
+	n := atomic.LoadInt32(&calls)
+	connect(t, ts, nil)
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt32(&calls) != n {
+		t.Error("called after close")
+	}
+}
+
+func TestEveryInvalidInterval(t *testing.T) {
+	var s Server
+	stop := s.Every(0, func() (Event, bool) {
+		t.Error("called with zero interval")
+		return Event{}, false
+	})
+	stop()
+}
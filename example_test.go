@@ -15,17 +15,16 @@ func Example() {
 		Time time.Time `json:"time"`
 	}
 
-	var sse = new(sse.Server)
-	go func() {
-		var id int
-		for range time.Tick(5 * time.Second) {
-			id++
-			_ = sse.Event(fmt.Sprintf("%04d", id), "event", &Event{
-				ID:   id,
-				Time: time.Now().Truncate(time.Second),
-			})
-		}
-	}()
-	http.Handle("/events", sse)
+	var server = new(sse.Server)
+	var id int
+	server.Every(5*time.Second, func() (sse.Event, bool) {
+		id++
+		e, err := sse.NewEvent(fmt.Sprintf("%04d", id), "event", &Event{
+			ID:   id,
+			Time: time.Now().Truncate(time.Second),
+		})
+		return e, err == nil
+	})
+	http.Handle("/events", server)
 	log.Fatal(http.ListenAndServe(":8000", nil))
 }
@@ -0,0 +1,103 @@
+package sse
+
+import "time"
+
+// FlushPolicy decides when the events written to the connection are flushed
+// to the client. Flushing after every event, as the server does by default,
+// gives the lowest latency; flushing the batches saves the system calls and
+// the TCP packets of the busy streams at the cost of the event delay. The
+// comments, including the heartbeats, are always flushed right away together
+// with the written events.
+type FlushPolicy interface {
+	// Flush reports whether to flush the connection after the event is
+	// written: pending is the number of the events written since the last
+	// flush and queued is the number of the events waiting in the queue of
+	// the connection.
+	Flush(pending, queued int) bool
+	// Delay returns the longest time the written events wait for the flush.
+	Delay() time.Duration
+}
+
+// WithFlushPolicy sets the flush policy of the connections of the server. The
+// flush policy of the client class (see WithClientClasses) takes precedence.
+//
+//	sse.WithFlushPolicy(sse.FlushAdaptive(50 * time.Millisecond))
+func WithFlushPolicy(p FlushPolicy) Option {
+	return func(s *Server) {
+		s.flushPolicy = p
+	}
+}
+
+// defaultFlushDelay is the flush delay of the policies without one.
+const defaultFlushDelay = 100 * time.Millisecond
+
+// FlushEvent returns the policy flushing the connection after every event, as
+// the server does by default.
+func FlushEvent() FlushPolicy {
+	return flushEvent{}
+}
+
+type flushEvent struct{}
+
+func (flushEvent) Flush(int, int) bool  { return true }
+func (flushEvent) Delay() time.Duration { return 0 }
+
+// FlushBatch returns the policy flushing the connection after every size
+// events, the waiting ones at most the delay later; 100ms if the delay is zero.
+func FlushBatch(size int, delay time.Duration) FlushPolicy {
+	if delay <= 0 {
+		delay = defaultFlushDelay
+	}
+	return flushBatch{size: size, delay: delay}
+}
+
+type flushBatch struct {
+	size  int
+	delay time.Duration
+}
+
+func (p flushBatch) Flush(pending, _ int) bool { return pending >= p.size }
+func (p flushBatch) Delay() time.Duration      { return p.delay }
+
+// FlushInterval returns the policy flushing the written events once in the
+// interval. The zero interval flushes every event.
+func FlushInterval(interval time.Duration) FlushPolicy {
+	if interval <= 0 {
+		return flushEvent{}
+	}
+	return flushBatch{size: int(^uint(0) >> 1), delay: interval}
+}
+
+// FlushAdaptive returns the policy following the queue depth of the
+// connection: the event is flushed right away when no more events are queued,
+// so the idle streams keep the lowest latency, while the events of the busy
+// ones are flushed together when the queue is drained, or at most the delay
+// later; 100ms if the delay is zero.
+func FlushAdaptive(delay time.Duration) FlushPolicy {
+	if delay <= 0 {
+		delay = defaultFlushDelay
+	}
+	return flushAdaptive{delay: delay}
+}
+
+type flushAdaptive struct {
+	delay time.Duration
+}
+
+// maxFlushBatch is the number of the events the adaptive policy writes at
+// most between the flushes.
+const maxFlushBatch = 256
+
+func (p flushAdaptive) Flush(pending, queued int) bool {
+	return queued == 0 || pending >= maxFlushBatch
+}
+
+func (p flushAdaptive) Delay() time.Duration { return p.delay }
+
+// flushing returns the flush policy of the client, nil to flush every event.
+func (s *Server) flushing(info ClientInfo) FlushPolicy {
+	if p := s.class(info).Flush; p != nil {
+		return p
+	}
+	return s.flushPolicy
+}
@@ -0,0 +1,63 @@
+package sse
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFlushPolicies(t *testing.T) {
+	for name, test := range map[string]struct {
+		policy          FlushPolicy
+		pending, queued int
+		flush           bool
+		delay           time.Duration
+	}{
+		"event":          {FlushEvent(), 1, 5, true, 0},
+		"batch":          {FlushBatch(3, 0), 2, 0, false, defaultFlushDelay},
+		"batch full":     {FlushBatch(3, time.Second), 3, 5, true, time.Second},
+		"interval":       {FlushInterval(time.Second), 1000, 0, false, time.Second},
+		"zero interval":  {FlushInterval(0), 1, 0, true, 0},
+		"adaptive busy":  {FlushAdaptive(time.Second), 1, 5, false, time.Second},
+		"adaptive idle":  {FlushAdaptive(0), 1, 0, true, defaultFlushDelay},
+		"adaptive batch": {FlushAdaptive(0), maxFlushBatch, 5, true, defaultFlushDelay},
+	} {
+		if flush := test.policy.Flush(test.pending, test.queued); flush != test.flush {
+			t.Errorf("%s flush %v", name, flush)
+		}
+		if d := test.policy.Delay(); d != test.delay {
+			t.Errorf("%s delay %v", name, d)
+		}
+	}
+
+	batch := FlushBatch(10, 0)
+	s := New(WithFlushPolicy(FlushEvent()), WithClientClasses(nil, map[string]ClientClass{"bot": {Flush: batch}}))
+	if s.flushing(ClientInfo{Class: "bot"}) != batch || s.flushing(ClientInfo{}) != FlushEvent() {
+		t.Error("class flush policy is not preferred")
+	}
+}
+
+func TestFlushBatch(t *testing.T) {
+	s := New(WithFlushPolicy(FlushBatch(2, 20*time.Millisecond)))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	s.Send(Event{Data: "1"})
+	s.Send(Event{Data: "2"})
+	for _, want := range []string{"data: 1\n", "data: 2\n"} {
+		if msg := readMessage(t, r); msg != want {
+			t.Errorf("message %q, want %q", msg, want)
+		}
+	}
+	start := time.Now()
+	s.Send(Event{Data: "3"}) // flushed after the delay
+	if msg := readMessage(t, r); msg != "data: 3\n" {
+		t.Errorf("delayed message %q", msg)
+	}
+	if d := time.Since(start); d < 15*time.Millisecond {
+		t.Errorf("flushed after %v", d)
+	}
+}
@@ -0,0 +1,84 @@
+package sse
+
+import (
+	"container/list"
+	"sync"
+)
+
+// maxCachedFrames is the maximum number of the encoded events kept for the
+// re-sends.
+const maxCachedFrames = 1024
+
+// frameKey is the key of the encoded event: the fields written to the stream.
+type frameKey struct {
+	name, id, data, trace string
+}
+
+// frameKeyOf returns the frame key of the event.
+func frameKeyOf(e Event) frameKey {
+	return frameKey{name: e.Name, id: e.ID, data: e.Data, trace: e.Trace}
+}
+
+// frameCache keeps the recently encoded events, so the replayed, retained and
+// acknowledgement awaiting events sent to each new connection are not encoded
+// again every time. The least recently used frames are evicted.
+type frameCache struct {
+	mu     sync.Mutex
+	frames map[frameKey]*list.Element // cached frames by key
+	order  list.List                  // frames from the least recently used
+}
+
+// cachedFrame is the encoded event in the cache.
+type cachedFrame struct {
+	key  frameKey
+	data string
+}
+
+// get returns the cached encoded event.
+func (f *frameCache) get(key frameKey) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	el, ok := f.frames[key]
+	if !ok {
+		return "", false
+	}
+	f.order.MoveToBack(el)
+	return el.Value.(*cachedFrame).data, true
+}
+
+// put adds the encoded event to the cache, evicting the least recently used
+// one if the cache is full.
+func (f *frameCache) put(key frameKey, data string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if el, ok := f.frames[key]; ok {
+		f.order.MoveToBack(el)
+		return
+	}
+	if f.frames == nil {
+		f.frames = make(map[frameKey]*list.Element)
+	}
+	f.frames[key] = f.order.PushBack(&cachedFrame{key: key, data: data})
+	if f.order.Len() > maxCachedFrames {
+		oldest := f.order.Front()
+		f.order.Remove(oldest)
+		delete(f.frames, oldest.Value.(*cachedFrame).key)
+	}
+}
+
+// frame returns the encoded event from the cache or encodes and caches it.
+func (s *Server) frame(e Event) string {
+	key := frameKeyOf(e)
+	if data, ok := s.frames.get(key); ok {
+		return data
+	}
+	data := s.encode(e)
+	s.frames.put(key, data)
+	return data
+}
+
+// resent reports whether the broadcast events may be sent again to the new
+// connections, so their frames are worth caching.
+func (s *Server) resent() bool {
+	return s.store != nil || s.retainKey != nil
+}
@@ -0,0 +1,62 @@
+package sse
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestFrameCache(t *testing.T) {
+	var f frameCache
+	for i := 0; i < maxCachedFrames; i++ {
+		f.put(frameKey{id: strconv.Itoa(i)}, "id: "+strconv.Itoa(i)+"\n")
+	}
+	if _, ok := f.get(frameKey{id: "0"}); !ok { // the oldest is used again
+		t.Fatal("frame is not cached")
+	}
+	f.put(frameKey{id: "new"}, "id: new\n")
+	if _, ok := f.get(frameKey{id: "1"}); ok {
+		t.Error("least recently used frame is not evicted")
+	}
+	if data, ok := f.get(frameKey{id: "0"}); !ok || data != "id: 0\n" {
+		t.Errorf("recently used frame %q", data)
+	}
+	if n := f.order.Len(); n != maxCachedFrames || len(f.frames) != n {
+		t.Errorf("%d frames cached", n)
+	}
+}
+
+func TestFrameCacheResent(t *testing.T) {
+	s := New(WithRetain(nil))
+	s.Send(Event{Name: "status", Data: "ready"})
+	key := frameKey{name: "status", data: "ready"}
+	if data, ok := s.frames.get(key); !ok || data != "event: status\ndata: ready\n" {
+		t.Fatalf("broadcast frame %q is not cached", data)
+	}
+
+	c := &client{done: make(chan struct{})}
+	s.retainedEvents(c)
+	if len(c.initial) != 1 || c.initial[0] != "event: status\ndata: ready\n" {
+		t.Errorf("initial messages %q", c.initial)
+	}
+
+	traced := Event{ID: "1", Data: "order", Trace: traceparent}
+	s.Send(traced)
+	untraced := traced
+	untraced.Trace = ""
+	s.Send(untraced)
+	if data := s.frame(traced); data != "data: order\ntrace: "+traceparent+"\nid: 1\n" {
+		t.Errorf("traced frame %q", data)
+	}
+	if data := s.frame(untraced); data != "data: order\nid: 1\n" {
+		t.Errorf("untraced frame %q", data)
+	}
+	if _, ok := s.frames.get(frameKeyOf(traced)); !ok {
+		t.Error("traced frame is not cached")
+	}
+
+	plain := New()
+	plain.Send(Event{Data: "live"})
+	if _, ok := plain.frames.get(frameKey{data: "live"}); ok {
+		t.Error("frame of the event not sent again is cached")
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/mdigger/sse
 
-go 1.16
+go 1.22
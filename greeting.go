@@ -0,0 +1,45 @@
+package sse
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WithGreeting enables the greeting event with the given name sent first to
+// each new connection. Its data is the JSON object with the client identity,
+// the server time and the negotiated stream parameters, so the clients can
+// identify their connection when calling other APIs:
+//
+//	{"client_id":"...","time":"...","channels":["..."],"rate_limit":10}
+func WithGreeting(name string) Option {
+	return func(s *Server) {
+		s.greeting = name
+	}
+}
+
+// greeting is the data of the greeting event.
+type greeting struct {
+	ClientID  string    `json:"client_id"`
+	Time      time.Time `json:"time"`
+	Channels  []string  `json:"channels,omitempty"`
+	RateLimit float64   `json:"rate_limit,omitempty"`
+}
+
+// greet adds the greeting event to the initial messages of the client.
+func (s *Server) greet(c *client) {
+	if s.greeting == "" {
+		return
+	}
+	data, err := json.Marshal(greeting{
+		ClientID:  c.info.ID,
+		Time:      time.Now().UTC(),
+		Channels:  c.info.Channels,
+		RateLimit: s.rate,
+	})
+	if err != nil {
+		return
+	}
+	if data, ok := s.render(c, Event{Name: s.greeting, Data: string(data)}, ""); ok {
+		c.initial = append(c.initial, data)
+	}
+}
@@ -0,0 +1,37 @@
+package sse
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGreeting(t *testing.T) {
+	s := New(
+		WithGreeting("connected"),
+		WithClientID(func(r *http.Request) string { return "alice" }),
+		WithChannels(func(r *http.Request) []string { return []string{"news"} }),
+	)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	msg := readMessage(t, connect(t, ts, nil))
+	const prefix = "event: connected\ndata: "
+	if !strings.HasPrefix(msg, prefix) {
+		t.Fatalf("greeting %q", msg)
+	}
+	var g greeting
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(msg, prefix)), &g); err != nil {
+		t.Fatal(err)
+	}
+	if g.ClientID != "alice" || len(g.Channels) != 1 || g.Channels[0] != "news" {
+		t.Errorf("greeting %+v", g)
+	}
+	if time.Since(g.Time) > time.Minute {
+		t.Errorf("greeting time %v", g.Time)
+	}
+}
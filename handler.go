@@ -0,0 +1,105 @@
+package sse
+
+import "net/http"
+
+// HandlerOption configures the handler returned by Server.Handler.
+type HandlerOption func(*handler)
+
+// WithHeader adds the header to the events stream responses.
+func WithHeader(key, value string) HandlerOption {
+	return func(h *handler) {
+		h.header.Add(key, value)
+	}
+}
+
+// WithCORS allows the cross-origin requests from the given origins. The "*"
+// origin allows requests from any origin without credentials.
+func WithCORS(origins ...string) HandlerOption {
+	return func(h *handler) {
+		h.origins = append(h.origins, origins...)
+	}
+}
+
+// WithFilter sets the function which decides whether the event is delivered to
+// the client connected through the handler.
+func WithFilter(fn func(e Event, info ClientInfo) bool) HandlerOption {
+	return func(h *handler) {
+		h.filter = fn
+	}
+}
+
+// WithAuth sets the function authorizing the requests to the handler. The
+// unauthorized requests get the 401 Unauthorized status, the CORS preflight
+// requests are not authorized.
+func WithAuth(fn func(r *http.Request) bool) HandlerOption {
+	return func(h *handler) {
+		h.auth = fn
+	}
+}
+
+// Handler returns the handler of the events stream with the given options.
+// The same server can be mounted at several routes with different settings,
+// e.g. the public stream with filtered events and the internal firehose:
+//
+//	mux.Handle("/events", s.Handler(sse.WithCORS("*"), sse.WithFilter(public)))
+//	mux.Handle("/internal/events", s.Handler(sse.WithAuth(isStaff)))
+func (s *Server) Handler(opts ...HandlerOption) http.Handler {
+	h := &handler{server: s, header: make(http.Header)}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// handler serves the events stream of the server with the mount options.
+type handler struct {
+	server  *Server
+	header  http.Header                  // additional response headers
+	origins []string                     // allowed CORS origins
+	filter  func(Event, ClientInfo) bool // events filter
+	auth    func(*http.Request) bool     // requests authorization
+	later   *comeBackLater               // advisory response instead of the stream
+
+	// join, if set, is called to register the client instead of the server,
+	// e.g. to add the initial messages atomically with the registration
+	join func(c *client, register func(*client)) error
+}
+
+// ServeHTTP implements http.Handler interface.
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.server.serve(w, r, h)
+}
+
+// prepare sets the response headers of the handler and reports whether the
+// CORS preflight request is served.
+func (h *handler) prepare(w http.ResponseWriter, r *http.Request) bool {
+	for k, v := range h.header {
+		w.Header()[k] = append(w.Header()[k], v...)
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" || len(h.origins) == 0 {
+		return false
+	}
+	for _, allowed := range h.origins {
+		switch allowed {
+		case "*":
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		case origin:
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Add("Vary", "Origin")
+		default:
+			continue
+		}
+
+		if r.Method != http.MethodOptions {
+			return false
+		}
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		w.Header().Set("Access-Control-Allow-Headers", "Last-Event-ID, Cache-Control")
+		w.WriteHeader(http.StatusNoContent)
+		return true
+	}
+	return false
+}
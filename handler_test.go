@@ -0,0 +1,94 @@
+package sse
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	s := New()
+	mux := http.NewServeMux()
+	mux.Handle("/public", s.Handler(
+		WithCORS("https://example.com"),
+		WithHeader("X-Stream", "public"),
+		WithFilter(func(e Event, _ ClientInfo) bool { return e.Name != "internal" }),
+	))
+	mux.Handle("/admin", s.Handler())
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	defer s.Close()
+
+	get := func(path string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest("GET", ts.URL+path, nil)
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("Origin", "https://example.com")
+		res, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { res.Body.Close() })
+		return res
+	}
+
+	public := get("/public")
+	if v := public.Header.Get("Access-Control-Allow-Origin"); v != "https://example.com" {
+		t.Errorf("Access-Control-Allow-Origin %q", v)
+	}
+	if v := public.Header.Get("X-Stream"); v != "public" {
+		t.Errorf("X-Stream %q", v)
+	}
+	admin := get("/admin")
+	if v := admin.Header.Get("Access-Control-Allow-Origin"); v != "" {
+		t.Errorf("admin Access-Control-Allow-Origin %q", v)
+	}
+	waitConnected(t, s, 2)
+
+	go func() {
+		s.Send(Event{Name: "internal", Data: "secret"})
+		s.Send(Event{Name: "news", Data: "hello"})
+	}()
+	if msg := readMessage(t, bufio.NewReader(public.Body)); msg != "event: news\ndata: hello\n" {
+		t.Errorf("public got %q", msg)
+	}
+	adminBody := bufio.NewReader(admin.Body)
+	if msg := readMessage(t, adminBody); msg != "event: internal\ndata: secret\n" {
+		t.Errorf("admin got %q", msg)
+	}
+
+	req, _ := http.NewRequest("OPTIONS", ts.URL+"/public", nil)
+	req.Header.Set("Origin", "https://example.com")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		t.Errorf("preflight status %s", res.Status)
+	}
+}
+
+func TestHandlerAuth(t *testing.T) {
+	s := New()
+	ts := httptest.NewServer(s.Handler(WithAuth(func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer staff"
+	})))
+	defer ts.Close()
+	defer s.Close()
+
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status %s", res.Status)
+	}
+
+	connect(t, ts, http.Header{"Authorization": {"Bearer staff"}})
+	waitConnected(t, s, 1)
+}
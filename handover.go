@@ -0,0 +1,176 @@
+package sse
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// stateVersion is the version of the exported state format.
+const stateVersion = 1
+
+// serverState is the state of the server transferred to its successor.
+type serverState struct {
+	Version  int             `json:"version"`
+	Retained []retainedState `json:"retained,omitempty"`
+	Replay   *replayState    `json:"replay,omitempty"`
+	Acks     []ackState      `json:"acks,omitempty"`
+	Config   configState     `json:"config"`
+}
+
+// retainedState is the retained event with its key.
+type retainedState struct {
+	Key   string `json:"key"`
+	Event Event  `json:"event"`
+}
+
+// replayState is the replay buffer of the MemoryStore.
+type replayState struct {
+	Seq    uint64        `json:"seq"`
+	Events []storedState `json:"events"`
+}
+
+// storedState is the event of the replay buffer with the time it is stored.
+type storedState struct {
+	Event  Event     `json:"event"`
+	Stored time.Time `json:"stored"`
+}
+
+// ackState is the events waiting for the acknowledgement of the client.
+type ackState struct {
+	Client string  `json:"client"`
+	Events []Event `json:"events"`
+}
+
+// configState is the snapshot of the server configuration, informational
+// only: the importing server keeps its own options.
+type configState struct {
+	Buffer    int           `json:"buffer"`
+	Policy    int           `json:"policy"`
+	Heartbeat time.Duration `json:"heartbeat,omitempty"`
+	MaxSize   int           `json:"maxSize,omitempty"`
+	ConnLimit int           `json:"connLimit,omitempty"`
+	Retain    bool          `json:"retain,omitempty"`
+}
+
+// ExportState writes the state of the server as JSON: the retained events,
+// the replay buffer and the sequence of the MemoryStore, the events waiting
+// for the acknowledgement and the snapshot of the configuration. ImportState
+// of the successor restores it, so the warm restarts and the blue/green
+// handovers keep the continuity of the replay for the reconnecting clients.
+// The stores other than MemoryStore keep the events themselves and are not
+// exported.
+func (s *Server) ExportState(w io.Writer) error {
+	state := serverState{
+		Version: stateVersion,
+		Config: configState{
+			Buffer:    s.buffer,
+			Policy:    int(s.policy),
+			Heartbeat: s.heartbeat,
+			MaxSize:   s.maxSize,
+			ConnLimit: s.connLimit,
+			Retain:    s.retainKey != nil,
+		},
+	}
+	s.replayMu.Lock()
+	for _, key := range s.retainOrder {
+		state.Retained = append(state.Retained, retainedState{Key: key, Event: s.retained[key]})
+	}
+	if m, ok := s.store.(*MemoryStore); ok {
+		state.Replay = m.export()
+	}
+	s.replayMu.Unlock()
+	s.acks.mu.Lock()
+	if s.acks.order != nil {
+		for el := s.acks.order.Front(); el != nil; el = el.Next() {
+			pending := el.Value.(*pendingAcks)
+			state.Acks = append(state.Acks, ackState{
+				Client: pending.client,
+				Events: append([]Event(nil), pending.events...),
+			})
+		}
+	}
+	s.acks.mu.Unlock()
+	return json.NewEncoder(w).Encode(state)
+}
+
+// ImportState restores the state written by ExportState, replacing the
+// retained events and the events waiting for the acknowledgement. The replay
+// buffer is restored only to the MemoryStore of the server, keeping the last
+// events that fit its size, and the sequence never goes back, so the
+// identifiers already sent to the clients are not assigned again. It should
+// be called before the server accepts the connections.
+func (s *Server) ImportState(r io.Reader) error {
+	var state serverState
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return err
+	}
+	if state.Version != stateVersion {
+		return fmt.Errorf("sse: unsupported state version %d", state.Version)
+	}
+	s.replayMu.Lock()
+	s.retained, s.retainOrder = nil, nil
+	for _, retained := range state.Retained {
+		if s.retained == nil {
+			s.retained = make(map[string]Event, len(state.Retained))
+		}
+		if _, ok := s.retained[retained.Key]; !ok {
+			s.retainOrder = append(s.retainOrder, retained.Key)
+		}
+		s.retained[retained.Key] = retained.Event
+	}
+	if m, ok := s.store.(*MemoryStore); ok && state.Replay != nil {
+		m.restore(state.Replay)
+	}
+	s.replayMu.Unlock()
+	s.acks.mu.Lock()
+	s.acks.pending = make(map[string]*list.Element, len(state.Acks))
+	s.acks.order = list.New()
+	for _, ack := range state.Acks {
+		if _, ok := s.acks.pending[ack.Client]; ok || len(ack.Events) == 0 {
+			continue
+		}
+		events := ack.Events
+		if len(events) > maxPending {
+			events = events[len(events)-maxPending:]
+		}
+		s.acks.pending[ack.Client] = s.acks.order.PushBack(&pendingAcks{client: ack.Client, events: events})
+		if s.acks.order.Len() > maxAckClients {
+			s.acks.forget(s.acks.order.Front())
+		}
+	}
+	s.acks.mu.Unlock()
+	return nil
+}
+
+// export returns the replay buffer of the store.
+func (m *MemoryStore) export() *replayState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state := &replayState{Seq: m.seq}
+	for _, e := range m.ordered() {
+		state.Events = append(state.Events, storedState{Event: e.Event, Stored: e.stored})
+	}
+	return state
+}
+
+// restore replaces the stored events with the ones of the replay buffer,
+// keeping the last events that fit the store.
+func (m *MemoryStore) restore(state *replayState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	events := state.Events
+	if len(events) > cap(m.events) {
+		events = events[len(events)-cap(m.events):]
+	}
+	m.events, m.start = m.events[:0], 0
+	for _, e := range events {
+		m.events = append(m.events, storedEvent{Event: e.Event, stored: e.Stored})
+	}
+	m.full = len(m.events) == cap(m.events)
+	if state.Seq > m.seq {
+		m.seq = state.Seq
+	}
+}
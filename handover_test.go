@@ -0,0 +1,55 @@
+package sse
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestExportState(t *testing.T) {
+	store := NewMemoryStore(10)
+	status := func(e Event) string { return e.Name }
+	s := New(WithRetain(status), WithEventStore(store), WithClientID(func(r *http.Request) string { return "" }))
+	defer s.Close()
+	s.Send(Event{Name: "status", Data: "ready"})
+	s.Send(Event{Data: "1", QoS: QoSStore})
+	s.Send(Event{Data: "2", QoS: QoSStore})
+	s.track(Event{ID: "2", Data: "2", QoS: QoSAck}, ClientInfo{ID: "alice"})
+
+	var buf bytes.Buffer
+	if err := s.ExportState(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// the successor keeps the last events fitting its store
+	next := NewMemoryStore(1)
+	s2 := New(WithRetain(status), WithEventStore(next))
+	defer s2.Close()
+	if err := s2.ImportState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if events := s2.Retained(); len(events) != 1 || events[0].Data != "ready" {
+		t.Errorf("retained %+v", events)
+	}
+	if events, _ := next.Since(""); len(events) != 1 || events[0].ID != "2" {
+		t.Errorf("replay %+v", events)
+	}
+	if e, _ := next.Append(Event{Data: "3"}); e.ID != "3" {
+		t.Errorf("next identifier %q", e.ID)
+	}
+	if events := s2.unacknowledged("alice"); len(events) != 1 || events[0].ID != "2" {
+		t.Errorf("unacknowledged %+v", events)
+	}
+}
+
+func TestImportStateVersion(t *testing.T) {
+	s := New()
+	defer s.Close()
+	if err := s.ImportState(strings.NewReader(`{"version":2}`)); err == nil {
+		t.Error("unsupported version imported")
+	}
+	if err := s.ImportState(strings.NewReader(`{`)); err == nil {
+		t.Error("malformed state imported")
+	}
+}
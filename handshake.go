@@ -0,0 +1,29 @@
+package sse
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// WithHandshakeTimeout bounds the time the connection may spend before the
+// first successful flush to the client: the client info hooks, the connect
+// events and the state snapshot. The request passed to the hooks has the
+// context with the deadline, so the hooks aware of it give up in time. The
+// connections exceeding the deadline get the 503 Service Unavailable status
+// instead of occupying a slot indefinitely.
+func WithHandshakeTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.handshakeTimeout = d
+	}
+}
+
+// handshake returns the request passed to the connection setup hooks, with the
+// handshake deadline if set.
+func (s *Server) handshake(r *http.Request, started time.Time) (*http.Request, context.CancelFunc) {
+	if s.handshakeTimeout <= 0 {
+		return r, func() {}
+	}
+	ctx, cancel := context.WithDeadline(r.Context(), started.Add(s.handshakeTimeout))
+	return r.WithContext(ctx), cancel
+}
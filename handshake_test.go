@@ -0,0 +1,83 @@
+package sse
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandshakeTimeout(t *testing.T) {
+	s := New(
+		WithHandshakeTimeout(20*time.Millisecond),
+		WithOnConnectEvents(func(r *http.Request, lastEventID string) []Event {
+			if lastEventID != "" {
+				<-r.Context().Done() // slow replay
+			}
+			return []Event{{Data: "hello"}}
+		}),
+	)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	if msg := readMessage(t, connect(t, ts, nil)); msg != "data: hello\n" {
+		t.Errorf("message %q", msg)
+	}
+
+	req, err := http.NewRequest("GET", ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Last-Event-ID", "1")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Error("status", res.Status)
+	}
+	waitConnected(t, s, 1)
+}
+
+func TestHandshakeTimeoutNotJoined(t *testing.T) {
+	changes := make(chan int, 10)
+	s := New(
+		WithHandshakeTimeout(20*time.Millisecond),
+		WithOnConnectEvents(func(r *http.Request, lastEventID string) []Event {
+			<-r.Context().Done() // slow replay
+			return nil
+		}),
+		WithOnConnectedChange(func(n int) { changes <- n }),
+	)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	req, err := http.NewRequest("GET", ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Error("status", res.Status)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if s.WaitForClients(ctx, 1) == nil {
+		t.Error("the rejected client has joined")
+	}
+	select {
+	case n := <-changes:
+		t.Errorf("connected changed to %d", n)
+	default:
+	}
+}
@@ -0,0 +1,98 @@
+package sse
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+)
+
+// SetMaintenance switches the maintenance mode of the server. In the
+// maintenance mode the new connections get the 503 Service Unavailable status
+// and the HealthHandler reports the server is not ready, while the connected
+// clients keep receiving the events until closed.
+func (s *Server) SetMaintenance(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&s.maintenance, v)
+}
+
+// Maintenance reports whether the server is in the maintenance mode.
+func (s *Server) Maintenance() bool {
+	return atomic.LoadInt32(&s.maintenance) == 1
+}
+
+// health is the health report of the server.
+type health struct {
+	Status     string  `json:"status"`     // ok, maintenance or unhealthy
+	Clients    int     `json:"clients"`    // connected clients
+	Queued     int     `json:"queued"`     // events queued for the clients
+	Saturation float64 `json:"saturation"` // ratio of the full client queues
+}
+
+// HealthHandler returns the handler of the readiness probe. It reports the
+// server status with the saturation indicators as JSON:
+//
+//	{"status":"ok","clients":120,"queued":14,"saturation":0.01}
+//
+// The status is "maintenance" in the maintenance mode and "unhealthy" when the
+// circuit breaker is tripped, with the 503 Service Unavailable response code,
+// so the load balancer stops routing the new connections to the server. Use
+// the LivenessHandler for the liveness probe: the draining server must not be
+// restarted.
+func (s *Server) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := s.health()
+		code := http.StatusOK
+		if h.Status != "ok" {
+			code = http.StatusServiceUnavailable
+		}
+		writeHealth(w, code, h)
+	})
+}
+
+// LivenessHandler returns the handler of the liveness probe. It reports the
+// same status as the HealthHandler, but always with the 200 OK response code:
+// the server in the maintenance mode or with the tripped circuit breaker is
+// still alive and keeps serving the connected clients.
+func (s *Server) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, http.StatusOK, s.health())
+	})
+}
+
+// health returns the health report of the server.
+func (s *Server) health() health {
+	var h health
+	var full int
+	s.mu.RLock()
+	h.Clients = len(s.clients)
+	for c := range s.clients {
+		h.Queued += len(c.messages)
+		if s.buffer > 0 && len(c.messages) == s.buffer {
+			full++
+		}
+	}
+	s.mu.RUnlock()
+	if h.Clients > 0 {
+		h.Saturation = float64(full) / float64(h.Clients)
+	}
+	switch {
+	case s.Maintenance():
+		h.Status = "maintenance"
+	case !s.Healthy():
+		h.Status = "unhealthy"
+	default:
+		h.Status = "ok"
+	}
+	return h
+}
+
+// writeHealth writes the health report with the response code.
+func writeHealth(w http.ResponseWriter, code int, h health) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(h)
+}
@@ -0,0 +1,63 @@
+package sse
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealthHandler(t *testing.T) {
+	s := New()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	check := func(code int, status string, clients int) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		s.HealthHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+		var h health
+		if err := json.Unmarshal(rec.Body.Bytes(), &h); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != code || h.Status != status || h.Clients != clients {
+			t.Errorf("health %d %+v, want %d %s with %d clients", rec.Code, h, code, status, clients)
+		}
+	}
+
+	connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	check(http.StatusOK, "ok", 1)
+
+	s.SetMaintenance(true)
+	check(http.StatusServiceUnavailable, "maintenance", 1)
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Error("connected in maintenance:", res.Status)
+	}
+
+	s.SetMaintenance(false)
+	connect(t, ts, nil)
+	check(http.StatusOK, "ok", 2)
+}
+
+func TestLivenessHandler(t *testing.T) {
+	s := New()
+	s.SetMaintenance(true)
+	rec := httptest.NewRecorder()
+	s.LivenessHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/livez", nil))
+	var h health
+	if err := json.Unmarshal(rec.Body.Bytes(), &h); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || h.Status != "maintenance" {
+		t.Errorf("liveness %d %+v", rec.Code, h)
+	}
+}
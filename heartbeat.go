@@ -0,0 +1,33 @@
+package sse
+
+import "time"
+
+// controlLane is the size of the queue of the comments of the connection.
+// The retry directives are not queued: the latest one replaces the pending.
+const controlLane = 4
+
+// WithHeartbeat makes every connection write the empty comment each interval
+// d, so the proxies don't close the idle streams and the clients notice the
+// dead connections (see Client.Idle). The heartbeats, as the comments and the
+// retry directives, are written ahead of the queued events, so a client with
+// the full queue still receives them. Zero disables the heartbeats.
+func WithHeartbeat(d time.Duration) Option {
+	return func(s *Server) {
+		s.heartbeat = d
+	}
+}
+
+// heartbeatComment is the message of the heartbeat.
+const heartbeatComment = ":\n"
+
+// sendControl puts the comment to the lane of all registered clients. The
+// comment is dropped for the clients whose lane is full: it already holds the
+// pending comments.
+func (s *Server) sendControl(data string) {
+	s.each(func(c *client) {
+		select {
+		case c.control <- data:
+		default:
+		}
+	})
+}
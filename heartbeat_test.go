@@ -0,0 +1,81 @@
+package sse
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHeartbeat(t *testing.T) {
+	s := New(WithHeartbeat(10 * time.Millisecond))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil)
+	for i := 0; i < 2; i++ {
+		if msg := readMessage(t, r); msg != ":\n" {
+			t.Fatalf("heartbeat %q", msg)
+		}
+	}
+}
+
+func TestControlLane(t *testing.T) {
+	s := New(WithRateLimit(10, 1), WithBufferSize(4))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	for _, data := range []string{"1", "2", "3"} {
+		s.Send(Event{Data: data})
+	}
+	s.Comment("ahead")
+
+	// the paced events stay queued while the comment is written
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, readMessage(t, r))
+	}
+	var events []string
+	for _, msg := range got {
+		if msg != ": ahead\n" {
+			events = append(events, msg)
+		}
+	}
+	if len(events) != 3 || events[0] != "data: 1\n" || events[2] != "data: 3\n" || got[3] == ": ahead\n" {
+		t.Errorf("received %q", got)
+	}
+}
+
+func TestControlRateLimited(t *testing.T) {
+	s := New(WithRateLimit(1, 1), WithBufferSize(4), WithHeartbeat(20*time.Millisecond))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	s.Send(Event{Data: "1"})
+	s.Send(Event{Data: "2"}) // waits a second for the rate limit
+	for msg := readMessage(t, r); msg != "data: 1\n"; msg = readMessage(t, r) {
+	}
+	s.Retry(time.Second)
+	s.Retry(2 * time.Second)
+	var retries, beats int
+	for beats < 2 {
+		switch msg := readMessage(t, r); msg {
+		case ":\n":
+			beats++
+		case "retry: 2000\n":
+			retries++
+		case "retry: 1000\n": // written before the second one
+		default:
+			t.Fatalf("message %q before the heartbeats", msg)
+		}
+	}
+	if retries != 1 {
+		t.Errorf("%d retry directives", retries)
+	}
+}
@@ -0,0 +1,34 @@
+package sse
+
+import (
+	"context"
+	"runtime/pprof"
+	"strings"
+)
+
+// WithProfilerLabels makes the goroutine serving the connection carry the
+// pprof labels sse_client, sse_channel and sse_remote with the client
+// identity, the subscribed channels and the network address of the client, so
+// the goroutine and CPU profiles of the busy server are attributed to the
+// streams:
+//
+//	go tool pprof -tagfocus sse_client=alice http://localhost:6060/debug/pprof/profile
+func WithProfilerLabels() Option {
+	return func(s *Server) {
+		s.labels = true
+	}
+}
+
+// label sets the pprof labels of the client on the current goroutine and
+// returns the function restoring the labels of the context.
+func (s *Server) label(ctx context.Context, info ClientInfo) func() {
+	if !s.labels {
+		return func() {}
+	}
+	pprof.SetGoroutineLabels(pprof.WithLabels(ctx, pprof.Labels(
+		"sse_client", info.ID,
+		"sse_channel", strings.Join(info.Channels, ","),
+		"sse_remote", info.RemoteAddr,
+	)))
+	return func() { pprof.SetGoroutineLabels(ctx) }
+}
@@ -0,0 +1,32 @@
+package sse
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"runtime/pprof"
+	"strings"
+	"testing"
+)
+
+func TestProfilerLabels(t *testing.T) {
+	s := New(WithProfilerLabels(),
+		WithClientID(func(r *http.Request) string { return "alice" }),
+		WithChannels(func(*http.Request) []string { return []string{"orders", "news"} }),
+	)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	var profile bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&profile, 1); err != nil {
+		t.Fatal(err)
+	}
+	for _, label := range []string{`"sse_client":"alice"`, `"sse_channel":"orders,news"`, `"sse_remote":"127.0.0.1:`} {
+		if !strings.Contains(profile.String(), label) {
+			t.Errorf("no %s label", label)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package sse
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// comeBackLater is the advisory response of the handler not streaming the
+// events now.
+type comeBackLater struct {
+	retry   time.Duration
+	comment string
+	when    func(*http.Request) bool
+}
+
+// WithComeBackLater makes the handler answer the requests accepted by the
+// function with the valid events stream carrying only the retry directive and
+// the comment, closed right away:
+//
+//	retry: 60000
+//	: streaming is not enabled yet
+//
+// The EventSource clients reconnect politely after the retry delay instead of
+// reporting the error, which suits the feature-flagged rollouts and the "come
+// back later" responses. A nil function accepts all requests.
+func WithComeBackLater(retry time.Duration, comment string, when func(r *http.Request) bool) HandlerOption {
+	return func(h *handler) {
+		h.later = &comeBackLater{retry: retry, comment: comment, when: when}
+	}
+}
+
+// serve answers with the advisory stream and reports whether the request is
+// served.
+func (l *comeBackLater) serve(w http.ResponseWriter, r *http.Request) bool {
+	if l == nil || (l.when != nil && !l.when(r)) {
+		return false
+	}
+	w.Header().Set("Content-Type", mimetype)
+	w.Header().Set("Cache-Control", "no-cache")
+	var buf strings.Builder
+	if l.retry > 0 {
+		buf.WriteString(retryField(l.retry))
+	}
+	writeLines(&buf, ": ", l.comment)
+	buf.WriteByte('\n')
+	_, _ = w.Write([]byte(buf.String()))
+	return true
+}
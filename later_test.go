@@ -0,0 +1,54 @@
+package sse
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestComeBackLater(t *testing.T) {
+	s := New()
+	ts := httptest.NewServer(s.Handler(WithComeBackLater(time.Minute, "not yet", func(r *http.Request) bool {
+		return r.URL.Query().Get("beta") == ""
+	})))
+	defer ts.Close()
+	defer s.Close()
+
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("response %s %q", res.Status, res.Header.Get("Content-Type"))
+	}
+	if string(body) != "retry: 60000\n: not yet\n\n" {
+		t.Errorf("body %q", body)
+	}
+	if n := s.Connected(); n != 0 {
+		t.Errorf("connected %d", n)
+	}
+
+	ts.URL += "?beta=1"
+	connect(t, ts, nil)
+	waitConnected(t, s, 1)
+}
+
+func TestComeBackLaterLineBreaks(t *testing.T) {
+	l := &comeBackLater{comment: "not\r\nyet\rtoday"}
+	w := httptest.NewRecorder()
+	if !l.serve(w, httptest.NewRequest("GET", "/", nil)) {
+		t.Fatal("not served")
+	}
+	if body := w.Body.String(); body != ": not\n: yet\n: today\n\n" {
+		t.Errorf("body %q", body)
+	}
+}
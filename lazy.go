@@ -0,0 +1,39 @@
+package sse
+
+import (
+	"context"
+	"sync"
+)
+
+// SendLazy sends the event with the given name to all connected clients, with
+// the data rendered for each recipient at the delivery time: localized or
+// trimmed according to the client permissions. It returns the first render
+// error or ErrTooLarge if the rendered event exceeds the maximum size; the
+// clients whose data failed to render or is too large do not receive the
+// event.
+func (s *Server) SendLazy(name string, render func(info ClientInfo) (string, error)) error {
+	var (
+		mu       sync.Mutex
+		firstErr error
+		result   SendResult
+	)
+	s.each(func(c *client) {
+		data, err := render(c.info)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return
+		}
+		ok, err := s.sendEvent(context.Background(), c, Event{Name: name, Data: data}, "")
+		result.add(c.info, ok, err)
+		if err == ErrTooLarge && firstErr == nil {
+			firstErr = err
+		}
+	})
+	s.reportTooLarge(result)
+	s.audit(Event{Name: name}, result.Delivered)
+	return firstErr
+}
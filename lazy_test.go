@@ -0,0 +1,39 @@
+package sse
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendLazy(t *testing.T) {
+	s := New(WithClientID(func(r *http.Request) string { return r.Header.Get("X-User") }))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	alice := connect(t, ts, http.Header{"X-User": {"alice"}})
+	bob := connect(t, ts, http.Header{"X-User": {"bob"}})
+	waitConnected(t, s, 2)
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.SendLazy("hello", func(info ClientInfo) (string, error) {
+			if info.ID == "bob" {
+				return "", errors.New("no template")
+			}
+			return "hello " + info.ID, nil
+		})
+		s.Send(Event{Data: "next"})
+	}()
+	if msg := readMessage(t, alice); msg != "event: hello\ndata: hello alice\n" {
+		t.Errorf("alice got %q", msg)
+	}
+	if msg := readMessage(t, bob); msg != "data: next\n" {
+		t.Errorf("bob got %q", msg)
+	}
+	if err := <-errc; err == nil || err.Error() != "no template" {
+		t.Errorf("error %v", err)
+	}
+}
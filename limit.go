@@ -0,0 +1,207 @@
+package sse
+
+import (
+	"container/list"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// WithRateLimit limits the number of events per second delivered to each
+// client, allowing bursts of up to burst events. Events beyond the rate wait in
+// the client queue (see WithBufferSize) and are subject to the slow client
+// policy when the queue is full. This protects clients, mostly mobile, from
+// being flooded when the server emits bursts. A non-positive limit disables
+// the rate limit.
+func WithRateLimit(limit float64, burst int) Option {
+	return func(s *Server) {
+		if limit <= 0 {
+			s.rate, s.burst = 0, 0
+			return
+		}
+		s.rate = limit
+		s.burst = burst
+	}
+}
+
+// limiter is a token bucket rate limiter.
+type limiter struct {
+	rate   float64   // tokens per second
+	burst  float64   // bucket size
+	tokens float64   // available tokens
+	last   time.Time // last update time
+}
+
+// newLimiter returns a new limiter with the full bucket.
+func newLimiter(rate float64, burst int) *limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &limiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// refill adds the tokens accumulated since the last update.
+func (l *limiter) refill(now time.Time) {
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+}
+
+// allow takes one token if it is available. Otherwise it returns false and the
+// time to wait before the token is available.
+func (l *limiter) allow(now time.Time) (bool, time.Duration) {
+	l.refill(now)
+	if l.tokens >= 1 {
+		l.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// reserve takes one token and returns the time to wait before it is
+// available.
+func (l *limiter) reserve(now time.Time) time.Duration {
+	l.refill(now)
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// WithConnectRateLimit limits the rate of accepted connections for the whole
+// server, allowing bursts of up to burst connections. Connections beyond the
+// rate are rejected with 429 Too Many Requests and the Retry-After header,
+// smoothing the reconnect storms. A non-positive limit disables the rate
+// limit.
+func WithConnectRateLimit(limit float64, burst int) Option {
+	return func(s *Server) {
+		if limit <= 0 {
+			if s.accepts != nil {
+				s.accepts.global = nil
+			}
+			return
+		}
+		if s.accepts == nil {
+			s.accepts = new(acceptLimiter)
+		}
+		s.accepts.global = newLimiter(limit, burst)
+	}
+}
+
+// WithConnectRateLimitPerKey limits the rate of accepted connections with the
+// same key returned by the given function, allowing bursts of up to burst
+// connections. If the function is nil, the IP address of the client is used
+// as the key. Up to maxLimitKeys keys are tracked: the idle keys with the full
+// bucket and then the least recently used ones are forgotten. A non-positive
+// limit disables the rate limit.
+func WithConnectRateLimitPerKey(limit float64, burst int, key func(r *http.Request) string) Option {
+	return func(s *Server) {
+		if limit <= 0 {
+			if s.accepts != nil {
+				s.accepts.key = nil
+			}
+			return
+		}
+		if s.accepts == nil {
+			s.accepts = new(acceptLimiter)
+		}
+		if key == nil {
+			key = remoteIP
+		}
+		s.accepts.key = key
+		s.accepts.rate = limit
+		s.accepts.burst = burst
+		s.accepts.keys = make(map[string]*list.Element)
+		s.accepts.order = list.New()
+	}
+}
+
+// maxLimitKeys is the maximum number of the keys tracked by the per-key
+// connections rate limiter.
+const maxLimitKeys = 10000
+
+// remoteIP returns the IP address of the client.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// acceptLimiter limits the rate of accepted connections.
+type acceptLimiter struct {
+	global *limiter                     // server-wide limiter
+	key    func(r *http.Request) string // per-key limiter key
+	rate   float64                      // per-key rate
+	burst  int                          // per-key burst
+	keys   map[string]*list.Element     // per-key limiters
+	order  *list.List                   // of *keyLimiter, least recently used first
+	mu     sync.Mutex
+}
+
+// keyLimiter is the limiter of the connections with the key.
+type keyLimiter struct {
+	*limiter
+	key string
+}
+
+// allow reports whether the connection is allowed. Otherwise it returns the
+// time after which the client should retry.
+func (a *acceptLimiter) allow(r *http.Request, now time.Time) (bool, time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var keyLimit *limiter
+	if a.key != nil {
+		// forget the idle keys with the full bucket and the least recently
+		// used ones over the limit
+		for el := a.order.Front(); el != nil; el = a.order.Front() {
+			l := el.Value.(*keyLimiter)
+			if l.refill(now); l.tokens < l.burst && a.order.Len() < maxLimitKeys {
+				break
+			}
+			delete(a.keys, l.key)
+			a.order.Remove(el)
+		}
+		key := a.key(r)
+		if el, ok := a.keys[key]; ok {
+			a.order.MoveToBack(el)
+			keyLimit = el.Value.(*keyLimiter).limiter
+		} else {
+			keyLimit = newLimiter(a.rate, a.burst)
+			a.keys[key] = a.order.PushBack(&keyLimiter{limiter: keyLimit, key: key})
+		}
+		if ok, retry := keyLimit.allow(now); !ok {
+			return false, retry
+		}
+	}
+	if a.global != nil {
+		if ok, retry := a.global.allow(now); !ok {
+			if keyLimit != nil {
+				keyLimit.tokens++ // give back the unused token
+			}
+			return false, retry
+		}
+	}
+	return true, 0
+}
+
+// tooManyRequests replies with 429 Too Many Requests status and the
+// Retry-After header.
+func tooManyRequests(w http.ResponseWriter, retry time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+	http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+}
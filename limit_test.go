@@ -0,0 +1,133 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+	l := newLimiter(10, 2)
+	for i := 0; i < 2; i++ {
+		if d := l.reserve(now); d != 0 {
+			t.Fatalf("burst token %d delayed by %v", i, d)
+		}
+	}
+	if d := l.reserve(now); d != 100*time.Millisecond {
+		t.Errorf("delay %v, want 100ms", d)
+	}
+	// the bucket is refilled with time, but not over the burst size
+	now = now.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		if d := l.reserve(now); d != 0 {
+			t.Fatalf("refilled token %d delayed by %v", i, d)
+		}
+	}
+	if d := l.reserve(now); d <= 0 {
+		t.Error("the bucket overflows the burst size")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	s := New(WithRateLimit(20, 1), WithBufferSize(10))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		s.Send(Event{Data: "tick"})
+	}
+	for i := 0; i < 5; i++ {
+		readMessage(t, r)
+	}
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("5 events at 20/s delivered in %v", elapsed)
+	}
+}
+
+func TestConnectRateLimit(t *testing.T) {
+	s := New(
+		WithConnectRateLimit(1, 3),
+		WithConnectRateLimitPerKey(0.5, 1, func(r *http.Request) string {
+			return r.Header.Get("X-User")
+		}),
+	)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	get := func(user string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest("GET", ts.URL, nil)
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("X-User", user)
+		res, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { res.Body.Close() })
+		return res
+	}
+
+	if res := get("alice"); res.StatusCode != http.StatusOK {
+		t.Fatal("first connection rejected:", res.Status)
+	}
+	res := get("alice")
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Fatal("second connection of the same key accepted:", res.Status)
+	}
+	if retry := res.Header.Get("Retry-After"); retry != "2" {
+		t.Errorf("Retry-After %q, want 2", retry)
+	}
+	if res := get("bob"); res.StatusCode != http.StatusOK {
+		t.Fatal("connection of another key rejected:", res.Status)
+	}
+	if res := get("carol"); res.StatusCode != http.StatusOK {
+		t.Fatal("connection within the global burst rejected:", res.Status)
+	}
+	if res := get("dave"); res.StatusCode != http.StatusTooManyRequests {
+		t.Fatal("connection over the global burst accepted:", res.Status)
+	}
+}
+
+func TestConnectRateLimitDisabled(t *testing.T) {
+	s := New(WithConnectRateLimit(0, 1), WithConnectRateLimitPerKey(-1, 1, nil))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+	for i := 0; i < 3; i++ {
+		connect(t, ts, nil) // fails on 429
+	}
+}
+
+func TestConnectRateLimitKeys(t *testing.T) {
+	var a acceptLimiter
+	WithConnectRateLimitPerKey(1, 1, func(r *http.Request) string {
+		return r.Header.Get("X-User")
+	})(&Server{accepts: &a})
+	now := time.Now()
+	req := httptest.NewRequest("GET", "/", nil)
+	for i := 0; i <= maxLimitKeys; i++ {
+		req.Header.Set("X-User", strconv.Itoa(i))
+		a.allow(req, now)
+	}
+	if n := len(a.keys); n != maxLimitKeys {
+		t.Errorf("%d keys tracked", n)
+	}
+	if _, ok := a.keys["0"]; ok {
+		t.Error("the least recently used key is not forgotten")
+	}
+
+	// the idle keys are forgotten
+	a.allow(req, now.Add(time.Minute))
+	if n := len(a.keys); n != 1 {
+		t.Errorf("%d keys tracked after idle", n)
+	}
+}
@@ -0,0 +1,63 @@
+package sse
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Localize returns the middleware translating the event for the languages
+// of the client, parsed from the Accept-Language header of the connection in
+// the order of preference (see ClientInfo.Languages), so one broadcast serves
+// the notification streams of all locales:
+//
+//	s.Use(sse.Localize(func(e sse.Event, languages []string) sse.Event {
+//		e.Data = catalog.Translate(e.Data, languages)
+//		return e
+//	}))
+func Localize(fn func(e Event, languages []string) Event) Middleware {
+	return func(e Event, info ClientInfo) (Event, bool) {
+		return fn(e, info.Languages), true
+	}
+}
+
+// parseAcceptLanguage returns the language tags of the Accept-Language header
+// in the order of preference. The tags with the zero quality and the wildcard
+// are skipped; the ones listed first are preferred with the equal quality.
+func parseAcceptLanguage(header string) []string {
+	type language struct {
+		tag string
+		q   float64
+	}
+	var languages []language
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		tag := strings.TrimSpace(params[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			languages = append(languages, language{tag: tag, q: q})
+		}
+	}
+	if len(languages) == 0 {
+		return nil
+	}
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].q > languages[j].q
+	})
+	tags := make([]string, len(languages))
+	for i, l := range languages {
+		tags[i] = l.tag
+	}
+	return tags
+}
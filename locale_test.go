@@ -0,0 +1,51 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	for header, want := range map[string][]string{
+		"":                                 nil,
+		"de":                               {"de"},
+		"*":                                nil,
+		"da, en-GB;q=0.8, en;q=0.7":        {"da", "en-GB", "en"},
+		"en;q=0.5, fr, *;q=0.1, ru;q=0":    {"fr", "en"},
+		"pt-BR;q=0.9 , es;q=0.9, it;q=bad": {"it", "pt-BR", "es"},
+	} {
+		if got := parseAcceptLanguage(header); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: languages %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	greetings := map[string]string{"en": "hello", "fr": "bonjour"}
+	s := New()
+	s.Use(Localize(func(e Event, languages []string) Event {
+		for _, lang := range languages {
+			if text, ok := greetings[lang]; ok {
+				e.Data = text
+				break
+			}
+		}
+		return e
+	}))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	fr := connect(t, ts, http.Header{"Accept-Language": {"de, fr;q=0.9, en;q=0.8"}})
+	plain := connect(t, ts, nil)
+	waitConnected(t, s, 2)
+	s.Send(Event{Data: "greeting"})
+	if msg := readMessage(t, fr); msg != "data: bonjour\n" {
+		t.Errorf("french client %q", msg)
+	}
+	if msg := readMessage(t, plain); msg != "data: greeting\n" {
+		t.Errorf("client without languages %q", msg)
+	}
+}
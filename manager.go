@@ -0,0 +1,168 @@
+package sse
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Manager keeps the servers of the topics, such as the chat rooms, created on
+// demand with the same options. It is safe for concurrent use.
+type Manager struct {
+	opts   []Option
+	mu     sync.Mutex
+	topics map[string]*topic
+}
+
+// topic is the server of the topic with its handler.
+type topic struct {
+	server  *Server
+	handler http.Handler // handler of the topic route, created on the first request
+	serving int          // requests being served
+	demand  bool         // created for the request and removed when idle
+}
+
+// NewManager returns the manager creating the servers of the topics with the
+// given options.
+func NewManager(opts ...Option) *Manager {
+	return &Manager{opts: opts, topics: make(map[string]*topic)}
+}
+
+// Topic returns the server of the topic, created if necessary.
+func (m *Manager) Topic(name string) *Server {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.topics[name]
+	if !ok {
+		t = &topic{server: New(m.opts...)}
+		m.topics[name] = t
+	}
+	return t.server
+}
+
+// Topics returns the sorted names of the topics.
+func (m *Manager) Topics() []string {
+	m.mu.Lock()
+	names := make([]string, 0, len(m.topics))
+	for name := range m.topics {
+		names = append(names, name)
+	}
+	m.mu.Unlock()
+	sort.Strings(names)
+	return names
+}
+
+// Remove closes the server of the topic and forgets it.
+func (m *Manager) Remove(name string) {
+	m.mu.Lock()
+	t, ok := m.topics[name]
+	delete(m.topics, name)
+	m.mu.Unlock()
+	if ok {
+		t.server.Close()
+	}
+}
+
+// Close closes the servers of all topics.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	topics := m.topics
+	m.topics = make(map[string]*topic)
+	m.mu.Unlock()
+	for _, t := range topics {
+		t.server.Close()
+	}
+}
+
+// acquire returns the topic of the request and counts the request served, or
+// nil if the topic does not exist and is not allowed to be created.
+func (m *Manager) acquire(name string, r *http.Request, allow func(*http.Request, string) bool) *topic {
+	m.mu.Lock()
+	t, ok := m.topics[name]
+	if !ok {
+		m.mu.Unlock()
+		if allow == nil || !allow(r, name) {
+			return nil
+		}
+		m.mu.Lock()
+		if t, ok = m.topics[name]; !ok {
+			t = &topic{server: New(m.opts...), demand: true}
+			m.topics[name] = t
+		}
+	}
+	t.serving++
+	m.mu.Unlock()
+	return t
+}
+
+// release counts the request of the topic served, removing the topic created
+// on demand when it has no more requests.
+func (m *Manager) release(name string, t *topic) {
+	m.mu.Lock()
+	t.serving--
+	idle := t.demand && t.serving == 0 && m.topics[name] == t
+	if idle {
+		delete(m.topics, name)
+	}
+	m.mu.Unlock()
+	if idle {
+		t.server.Close()
+	}
+}
+
+// HandleTopics registers the handler of the events streams of the topics for
+// the route pattern with the topic wildcard, the last one if several, using the
+// given handler options for every topic:
+//
+//	sse.HandleTopics(mux, "GET /events/{room}", rooms, func(r *http.Request, room string) bool {
+//		return isMember(r, room)
+//	}, sse.WithCORS("https://example.com"))
+//	rooms.Topic("lobby").Send(e)
+//
+// The request to /events/lobby is served by the server of the "lobby" topic.
+// The topics created with Topic are served to everybody, like the server
+// handler; the request of the unknown topic creates its server only if allow
+// reports true, and gets 404 Not Found otherwise or if allow is nil. The
+// servers created for the requests are removed with their retained and replay
+// events when their last connection closes. It panics if the pattern has no
+// wildcard.
+func HandleTopics(mux *http.ServeMux, pattern string, mgr *Manager, allow func(r *http.Request, topic string) bool, opts ...HandlerOption) {
+	wildcard := topicWildcard(pattern)
+	if wildcard == "" {
+		panic("sse: pattern " + pattern + " has no topic wildcard")
+	}
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue(wildcard)
+		if name == "" {
+			http.NotFound(w, r)
+			return
+		}
+		t := mgr.acquire(name, r, allow)
+		if t == nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer mgr.release(name, t)
+		mgr.mu.Lock()
+		if t.handler == nil {
+			t.handler = t.server.Handler(opts...)
+		}
+		h := t.handler
+		mgr.mu.Unlock()
+		h.ServeHTTP(w, r)
+	})
+}
+
+// topicWildcard returns the name of the last wildcard of the route pattern.
+func topicWildcard(pattern string) string {
+	end := strings.LastIndex(pattern, "}")
+	if end < 0 {
+		return ""
+	}
+	start := strings.LastIndex(pattern[:end], "{")
+	if start < 0 {
+		return ""
+	}
+	return strings.TrimSuffix(pattern[start+1:end], "...")
+}
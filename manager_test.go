@@ -0,0 +1,113 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHandleTopics(t *testing.T) {
+	rooms := NewManager()
+	mux := http.NewServeMux()
+	HandleTopics(mux, "GET /events/{room}", rooms, func(r *http.Request, room string) bool {
+		return room != "secret"
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	defer rooms.Close()
+
+	url := ts.URL
+	ts.URL = url + "/events/lobby"
+	lobby := connect(t, ts, nil)
+	ts.URL = url + "/events/kitchen"
+	kitchen := connect(t, ts, nil)
+	waitConnected(t, rooms.Topic("lobby"), 1)
+	waitConnected(t, rooms.Topic("kitchen"), 1)
+	if topics := rooms.Topics(); !reflect.DeepEqual(topics, []string{"kitchen", "lobby"}) {
+		t.Errorf("topics %q", topics)
+	}
+
+	go rooms.Topic("lobby").Send(Event{Data: "hello lobby"})
+	go rooms.Topic("kitchen").Send(Event{Data: "hello kitchen"})
+	if msg := readMessage(t, lobby); msg != "data: hello lobby\n" {
+		t.Errorf("lobby %q", msg)
+	}
+	if msg := readMessage(t, kitchen); msg != "data: hello kitchen\n" {
+		t.Errorf("kitchen %q", msg)
+	}
+
+	rooms.Remove("kitchen")
+	if topics := rooms.Topics(); !reflect.DeepEqual(topics, []string{"lobby"}) {
+		t.Errorf("topics after remove %q", topics)
+	}
+	if code := topicStatus(t, url+"/events/secret"); code != http.StatusNotFound {
+		t.Errorf("not allowed topic status %d", code)
+	}
+}
+
+func TestHandleTopicsIdle(t *testing.T) {
+	rooms := NewManager()
+	defer rooms.Close()
+	rooms.Topic("lobby")
+	mux := http.NewServeMux()
+	HandleTopics(mux, "GET /events/{room}", rooms, func(r *http.Request, room string) bool {
+		return true
+	}, WithAuth(func(r *http.Request) bool { return r.URL.Query().Get("token") == "ok" }))
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	if code := topicStatus(t, ts.URL+"/events/lobby"); code != http.StatusUnauthorized {
+		t.Errorf("unauthorized status %d", code)
+	}
+	if code := topicStatus(t, ts.URL+"/events/kitchen?token=ok"); code != http.StatusOK {
+		t.Errorf("status %d", code)
+	}
+	// the topic created for the request is removed when idle
+	for deadline := time.Now().Add(time.Second); len(rooms.Topics()) != 1; {
+		if time.Now().After(deadline) {
+			t.Fatalf("topics %q", rooms.Topics())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if topics := rooms.Topics(); topics[0] != "lobby" {
+		t.Errorf("topics %q", topics)
+	}
+
+	empty := NewManager()
+	mux = http.NewServeMux()
+	HandleTopics(mux, "GET /events/{room}", empty, nil)
+	ts = httptest.NewServer(mux)
+	defer ts.Close()
+	if code := topicStatus(t, ts.URL+"/events/lobby"); code != http.StatusNotFound {
+		t.Errorf("unknown topic status %d", code)
+	}
+}
+
+// topicStatus requests the stream and returns the response status, closing
+// the stream.
+func topicStatus(t *testing.T, url string) int {
+	t.Helper()
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	return res.StatusCode
+}
+
+func TestTopicWildcard(t *testing.T) {
+	for pattern, want := range map[string]string{
+		"/events/{room}":            "room",
+		"GET /{org}/events/{topic}": "topic",
+		"/events/{path...}":         "path",
+		"/events/":                  "",
+	} {
+		if got := topicWildcard(pattern); got != want {
+			t.Errorf("%q: wildcard %q, want %q", pattern, got, want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package sse
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Metadata is the machine-readable metadata of the event sent in the comment
+// line with WithMetadata.
+type Metadata struct {
+	Time    time.Time `json:"ts"`                // time the event is sent
+	Channel string    `json:"channel,omitempty"` // channel of the event
+	Seq     uint64    `json:"seq"`               // event number of the connection
+}
+
+// metaPrefix is the prefix of the metadata comment line.
+const metaPrefix = ": meta "
+
+// WithMetadata adds the metadata comment line to each event sent to the
+// clients, giving the observability without polluting the data consumed by
+// the browsers, which ignore the comments:
+//
+//	: meta {"ts":"2024-01-02T15:04:05.123Z","channel":"orders","seq":42}
+//	event: order
+//	data: {"id":1}
+//
+// The sequence numbers the events of the connection, including the dropped
+// ones, so the gaps reveal the losses. The Decoder returns the metadata of the
+// event with Metadata.
+func WithMetadata() Option {
+	return func(s *Server) {
+		s.metadata = true
+	}
+}
+
+// withMetadata returns the encoded event with the metadata line, if enabled.
+func (s *Server) withMetadata(c *client, e Event, data string) string {
+	if !s.metadata {
+		return data
+	}
+	meta, err := json.Marshal(Metadata{
+		Time:    time.Now().UTC(),
+		Channel: e.Channel,
+		Seq:     atomic.AddUint64(&c.seq, 1),
+	})
+	if err != nil {
+		return data
+	}
+	return metaPrefix + string(meta) + "\n" + data
+}
+
+// metadataSize returns the largest size of the metadata comment line of the
+// event, with the widest time and sequence number.
+func (s *Server) metadataSize(e Event) int {
+	if !s.metadata {
+		return 0
+	}
+	meta, err := json.Marshal(Metadata{Time: widestTime, Channel: e.Channel, Seq: math.MaxUint64})
+	if err != nil {
+		return 0
+	}
+	return len(metaPrefix) + len(meta) + 1
+}
+
+// widestTime is the time of the widest JSON encoding, with all nanoseconds.
+var widestTime = time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC)
+
+// Metadata returns the metadata of the last decoded event sent by the server
+// with WithMetadata.
+func (d *Decoder) Metadata() (Metadata, bool) {
+	var meta Metadata
+	if d.meta == "" || json.Unmarshal([]byte(d.meta), &meta) != nil {
+		return Metadata{}, false
+	}
+	return meta, true
+}
+
+// metaComment returns the metadata of the comment line or empty string.
+func metaComment(line string) string {
+	if !strings.HasPrefix(line, metaPrefix) {
+		return ""
+	}
+	return line[len(metaPrefix):]
+}
@@ -0,0 +1,58 @@
+package sse
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetadata(t *testing.T) {
+	s := New(
+		WithMetadata(),
+		WithChannels(func(*http.Request) []string { return []string{"orders"} }),
+	)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	dec := NewDecoder(connect(t, ts, nil))
+	waitConnected(t, s, 1)
+	go func() {
+		s.Send(Event{Name: "order", Data: "1", Channel: "orders"})
+		s.Send(Event{Data: "2"})
+	}()
+	for i, want := range []Metadata{{Channel: "orders", Seq: 1}, {Seq: 2}} {
+		data := fmt.Sprint(i + 1)
+		e, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		meta, ok := dec.Metadata()
+		if !ok {
+			t.Fatalf("%d: no metadata", i)
+		}
+		if time.Since(meta.Time) > time.Minute {
+			t.Errorf("%d: time %v", i, meta.Time)
+		}
+		meta.Time = time.Time{}
+		if meta != want {
+			t.Errorf("%d: metadata %+v, want %+v", i, meta, want)
+		}
+		if e.Data != data {
+			t.Errorf("%d: data %q, want %q", i, e.Data, data)
+		}
+	}
+}
+
+func TestDecoderWithoutMetadata(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(": meta {\"seq\":1}\n\ndata: plain\n\n"))
+	if _, err := dec.Decode(); err != nil {
+		t.Fatal(err)
+	}
+	if meta, ok := dec.Metadata(); ok {
+		t.Errorf("metadata of the other block: %+v", meta)
+	}
+}
@@ -0,0 +1,41 @@
+package sse
+
+import "time"
+
+// MetricsSink receives the server metrics pushed by PushMetrics, for the
+// push-based observability stacks. The StatsD implements it.
+type MetricsSink interface {
+	// Gauge sets the current value of the metric.
+	Gauge(name string, value float64)
+	// Count adds the delta to the counter metric.
+	Count(name string, delta int64)
+	// Flush sends the metrics set since the previous flush.
+	Flush() error
+}
+
+// PushMetrics pushes the server metrics to the sink every interval d until
+// the returned stop function is called or the server is closed:
+//
+//   - "clients" gauge is the number of connected clients;
+//   - "events.sent" counts the events sent;
+//   - "events.delivered" counts the messages queued to the clients;
+//   - "events.dropped" counts the messages dropped for the slow clients;
+//   - "events.expired" counts the events expired in the client queues.
+//
+// The flush errors are reported to the WithOnError hook. A non-positive
+// interval pushes nothing and returns the no-op stop function.
+func (s *Server) PushMetrics(sink MetricsSink, d time.Duration) (stop func()) {
+	var last Stats
+	return s.every(d, func() {
+		stats := s.Stats()
+		sink.Gauge("clients", float64(stats.Clients))
+		sink.Count("events.sent", int64(stats.Sent-last.Sent))
+		sink.Count("events.delivered", int64(stats.Delivered-last.Delivered))
+		sink.Count("events.dropped", int64(stats.Dropped-last.Dropped))
+		sink.Count("events.expired", int64(stats.Expired-last.Expired))
+		if err := sink.Flush(); err != nil {
+			s.onWriteError(err, ClientInfo{})
+		}
+		last = stats
+	})
+}
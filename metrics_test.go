@@ -0,0 +1,61 @@
+package sse
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingSink records the pushed metrics.
+type recordingSink struct {
+	mu      sync.Mutex
+	gauges  map[string]float64
+	counts  map[string]int64
+	flushed chan struct{}
+}
+
+func (r *recordingSink) Gauge(name string, value float64) {
+	r.mu.Lock()
+	r.gauges[name] = value
+	r.mu.Unlock()
+}
+
+func (r *recordingSink) Count(name string, delta int64) {
+	r.mu.Lock()
+	r.counts[name] += delta
+	r.mu.Unlock()
+}
+
+func (r *recordingSink) Flush() error {
+	r.flushed <- struct{}{}
+	return nil
+}
+
+func TestPushMetrics(t *testing.T) {
+	s := New(WithBufferSize(1), WithSlowClientPolicy(SlowClientDrop))
+	defer s.Close()
+	c := &client{messages: make(chan message, 1), done: make(chan struct{})}
+	s.clients = map[*client]struct{}{c: {}}
+	s.Send(Event{Data: "1"})
+	s.Send(Event{Data: "2"}) // the queue is full
+
+	sink := &recordingSink{
+		gauges:  make(map[string]float64),
+		counts:  make(map[string]int64),
+		flushed: make(chan struct{}, 1),
+	}
+	stop := s.PushMetrics(sink, time.Millisecond)
+	<-sink.flushed
+	<-sink.flushed // the second push adds nothing
+	stop()
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if sink.gauges["clients"] != 1 {
+		t.Errorf("gauges %v", sink.gauges)
+	}
+	if sink.counts["events.sent"] != 2 || sink.counts["events.delivered"] != 1 ||
+		sink.counts["events.dropped"] != 1 || sink.counts["events.expired"] != 0 {
+		t.Errorf("counts %v", sink.counts)
+	}
+}
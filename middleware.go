@@ -0,0 +1,23 @@
+package sse
+
+// Middleware processes the event before the delivery to the client: it may
+// enrich, redact or rename the returned event, or drop it returning false.
+type Middleware func(e Event, info ClientInfo) (Event, bool)
+
+// Use adds the middleware applied to every event delivered to each client, in
+// the order they are added. It should be called before the server starts
+// serving the clients.
+func (s *Server) Use(mw ...Middleware) {
+	s.middleware = append(s.middleware, mw...)
+}
+
+// apply returns the event processed by the middleware chain.
+func (s *Server) apply(e Event, info ClientInfo) (Event, bool) {
+	for _, mw := range s.middleware {
+		var ok bool
+		if e, ok = mw(e, info); !ok {
+			return Event{}, false
+		}
+	}
+	return e, true
+}
@@ -0,0 +1,41 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUse(t *testing.T) {
+	s := New(WithClientID(func(r *http.Request) string { return r.Header.Get("X-User") }))
+	s.Use(
+		func(e Event, info ClientInfo) (Event, bool) {
+			return e, e.Name != "debug" || info.ID == "admin"
+		},
+		func(e Event, info ClientInfo) (Event, bool) {
+			e.Data += " for " + info.ID
+			return e, true
+		},
+	)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	admin := connect(t, ts, http.Header{"X-User": {"admin"}})
+	user := connect(t, ts, http.Header{"X-User": {"user"}})
+	waitConnected(t, s, 2)
+
+	go func() {
+		s.Send(Event{Name: "debug", Data: "trace"})
+		s.Send(Event{Data: "news"})
+	}()
+	if msg := readMessage(t, admin); msg != "event: debug\ndata: trace for admin\n" {
+		t.Errorf("admin got %q", msg)
+	}
+	if msg := readMessage(t, admin); msg != "data: news for admin\n" {
+		t.Errorf("admin got %q", msg)
+	}
+	if msg := readMessage(t, user); msg != "data: news for user\n" {
+		t.Errorf("user got %q", msg)
+	}
+}
@@ -0,0 +1,63 @@
+package sse
+
+import "sync"
+
+// migrations serializes the migrations, the only code locking two servers at
+// once, so the servers are locked in any order.
+var migrations sync.Mutex
+
+// Migrate moves the live connections of the client with the given identity to
+// the other server of the same process, such as when the room is re-sharded,
+// without dropping the HTTP connections. After the move the connections
+// receive the events sent by the other server only; no event of either server
+// is delivered partially, as the move is atomic with their sending. The
+// connection settings, such as the rate limit, stay of the original server,
+// the connections limit of the other server is not applied. It returns the
+// number of the moved connections.
+func (s *Server) Migrate(clientID string, to *Server) int {
+	if to == nil || to == s {
+		return 0
+	}
+	migrations.Lock()
+	defer migrations.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	to.mu.Lock()
+	defer to.mu.Unlock()
+
+	before, beforeTo := len(s.clients), len(to.clients)
+	var moved int
+	for c := range s.clients {
+		if c.info.ID != clientID {
+			continue
+		}
+		delete(s.clients, c)
+		s.release(c)
+		if to.clients == nil {
+			to.clients = make(map[*client]struct{})
+		}
+		to.clients[c] = struct{}{}
+		if to.connLimit > 0 && to.clientID != nil {
+			if to.conns == nil {
+				to.conns = make(map[string][]*client)
+			}
+			to.conns[c.info.ID] = append(to.conns[c.info.ID], c)
+		}
+		c.server.Store(to)
+		moved++
+	}
+	if moved == 0 {
+		return 0
+	}
+	if to.joined != nil {
+		close(to.joined) // wake up the waiting for clients
+		to.joined = nil
+	}
+	if s.onChange != nil && len(s.clients) != before {
+		s.notifyChange(len(s.clients))
+	}
+	if to.onChange != nil && len(to.clients) != beforeTo {
+		to.notifyChange(len(to.clients))
+	}
+	return moved
+}
@@ -0,0 +1,38 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	user := func(r *http.Request) string { return r.Header.Get("X-User") }
+	a, b := New(WithClientID(user)), New(WithClientID(user))
+	ts := httptest.NewServer(a)
+	defer ts.Close()
+	defer a.Close()
+	defer b.Close()
+
+	alice := connect(t, ts, http.Header{"X-User": {"alice"}})
+	connect(t, ts, http.Header{"X-User": {"bob"}})
+	waitConnected(t, a, 2)
+	if n := a.Migrate("alice", b); n != 1 {
+		t.Fatalf("moved %d", n)
+	}
+	if a.Connected() != 1 || b.Connected() != 1 {
+		t.Errorf("connected %d and %d", a.Connected(), b.Connected())
+	}
+	go func() {
+		a.Send(Event{Data: "old room"})
+		b.Send(Event{Data: "new room"})
+	}()
+	if msg := readMessage(t, alice); msg != "data: new room\n" {
+		t.Errorf("message %q", msg)
+	}
+
+	// the migrated connection leaves the new server
+	b.Close()
+	waitConnected(t, b, 0)
+	waitConnected(t, a, 1)
+}
@@ -0,0 +1,133 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	// mirrorBuffer is the number of the events waiting to be forwarded to
+	// each mirror target.
+	mirrorBuffer = 1024
+	// mirrorBackoff is the delay before the first retry of the failed
+	// forwarding, doubled for each next one.
+	mirrorBackoff = 100 * time.Millisecond
+	// maxMirrorBackoff is the maximum delay between the retries.
+	maxMirrorBackoff = 30 * time.Second
+)
+
+// ErrMirrorFull is reported to the WithOnError hook for the event not
+// forwarded to the mirror target, because the target is failing or too slow
+// and the buffer of its events is full.
+var ErrMirrorFull = errors.New("sse: mirror buffer is full")
+
+// Mirror forwards every event broadcast by the server to the targets, such as
+// the brokers of the other regions in the simple active-active setup, until
+// the context is done. The events are forwarded asynchronously and in order,
+// with the buffer of each target, so the slow or unreachable target does not
+// hold the local clients: the events not fitting the buffer are dropped and
+// ErrMirrorFull is reported to the WithOnError hook.
+//
+// The targets implementing SendContext, as the Server does, report the
+// forwarding errors: they are reported to the WithOnError hook and the event
+// is retried with the exponential backoff up to 30 seconds, unless it is too
+// large for the target (see ErrTooLarge) or rejected with the client error
+// status (see ErrBadStatus and Webhook). The other targets get the events with
+// Send. The targets must not mirror the events back.
+func (s *Server) Mirror(ctx context.Context, targets []Publisher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, target := range targets {
+		m := &mirror{target: target, events: make(chan Event, mirrorBuffer)}
+		s.mirrors = append(s.mirrors, m)
+		go s.forward(ctx, m)
+	}
+}
+
+// mirror is the target of the mirrored events.
+type mirror struct {
+	target Publisher
+	events chan Event // events waiting to be forwarded
+}
+
+// contextSender is the target reporting the forwarding errors.
+type contextSender interface {
+	SendContext(ctx context.Context, e Event) error
+}
+
+// mirror puts the broadcast event to the buffers of the mirror targets.
+func (s *Server) mirror(e Event) {
+	var full int // mirrors the event is dropped for
+	s.mu.RLock()
+	for _, m := range s.mirrors {
+		select {
+		case m.events <- e:
+		default:
+			full++
+		}
+	}
+	s.mu.RUnlock()
+	for ; full > 0; full-- {
+		s.onWriteError(ErrMirrorFull, ClientInfo{})
+	}
+}
+
+// forward sends the buffered events to the mirror target until the context
+// is done; then the target is removed.
+func (s *Server) forward(ctx context.Context, m *mirror) {
+	defer s.removeMirror(m)
+	sender, _ := m.target.(contextSender)
+	for {
+		select {
+		case e := <-m.events:
+			if sender == nil {
+				m.target.Send(e)
+				continue
+			}
+			for backoff := mirrorBackoff; ; backoff = min(2*backoff, maxMirrorBackoff) {
+				err := sender.SendContext(ctx, e)
+				if err == nil || ctx.Err() != nil {
+					break
+				}
+				s.onWriteError(err, ClientInfo{})
+				if !retryable(err) {
+					break // not accepted by the target ever
+				}
+				timer := clockOrSystem(s.clock).NewTimer(backoff)
+				select {
+				case <-timer.C():
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// retryable reports whether the forwarding error may pass with the retry: the
+// events too large and rejected by the target are not retried.
+func retryable(err error) bool {
+	var status *ErrBadStatus
+	if errors.As(err, &status) {
+		return status.Code/100 != 4 ||
+			status.Code == http.StatusRequestTimeout || status.Code == http.StatusTooManyRequests
+	}
+	return !errors.Is(err, ErrTooLarge)
+}
+
+// removeMirror removes the mirror target of the server.
+func (s *Server) removeMirror(m *mirror) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, other := range s.mirrors {
+		if other == m {
+			s.mirrors = append(s.mirrors[:i:i], s.mirrors[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyTarget fails the first sends of the events.
+type flakyTarget struct {
+	*Server
+	mu       sync.Mutex
+	failures int
+	sent     chan Event
+}
+
+func (f *flakyTarget) SendContext(ctx context.Context, e Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("unreachable")
+	}
+	f.sent <- e
+	return nil
+}
+
+func TestMirror(t *testing.T) {
+	var mu sync.Mutex
+	var reported []error
+	s := New(WithEventStore(NewMemoryStore(10)), WithOnError(func(err error, info ClientInfo) {
+		mu.Lock()
+		reported = append(reported, err)
+		mu.Unlock()
+	}))
+	defer s.Close()
+	flaky := &flakyTarget{Server: New(), failures: 2, sent: make(chan Event, 2)}
+	plain := New(WithRetain(nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Mirror(ctx, []Publisher{flaky, plainPublisher{plain}})
+
+	s.Send(Event{Data: "1", QoS: QoSStore})
+	s.Send(Event{Data: "2"})
+	_ = s.EventTo("alice", "", "", "private") // not broadcast
+	for _, want := range []Event{{ID: "1", Data: "1", QoS: QoSStore}, {Data: "2"}} {
+		select {
+		case e := <-flaky.sent:
+			if e != want {
+				t.Errorf("forwarded %+v, want %+v", e, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("event is not forwarded")
+		}
+	}
+	mu.Lock()
+	if len(reported) != 2 {
+		t.Errorf("reported %v", reported)
+	}
+	mu.Unlock()
+	for deadline := time.Now().Add(time.Second); len(plain.Retained()) != 1; {
+		if time.Now().After(deadline) {
+			t.Fatal("event is not sent to the plain target")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	for deadline := time.Now().Add(time.Second); ; {
+		s.mu.RLock()
+		n := len(s.mirrors)
+		s.mu.RUnlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d mirrors after the cancel", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// plainPublisher hides the SendContext of the server.
+type plainPublisher struct {
+	Publisher
+}
+
+func TestMirrorFull(t *testing.T) {
+	var full int
+	var s *Server
+	s = New(WithOnError(func(err error, info ClientInfo) {
+		if err == ErrMirrorFull {
+			full++
+		}
+		if s.Connected() != 0 { // the hook may use the server
+			t.Error("connected clients")
+		}
+	}))
+	s.mirrors = []*mirror{{target: New(), events: make(chan Event, 1)}}
+	s.Send(Event{Data: "1"})
+	s.Send(Event{Data: "2"})
+	if full != 1 {
+		t.Errorf("%d events dropped", full)
+	}
+}
@@ -0,0 +1,86 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// SourceEvent is the event received by the MultiSubscriber with the label of
+// its source.
+type SourceEvent struct {
+	Source string
+	Event
+}
+
+// MultiSubscriber receives the events from several streams, such as the
+// upstreams of the aggregator service, and merges them into one channel in
+// the order they are received.
+type MultiSubscriber struct {
+	clients map[string]*Client
+}
+
+// NewMultiSubscriber returns the subscriber of the streams with the given URLs
+// by the source labels. The options apply to the clients of all streams.
+func NewMultiSubscriber(sources map[string]string, opts ...ClientOption) *MultiSubscriber {
+	m := &MultiSubscriber{clients: make(map[string]*Client, len(sources))}
+	for source, url := range sources {
+		m.clients[source] = NewClient(url, opts...)
+	}
+	return m
+}
+
+// Client returns the client of the source, such as to check its error after
+// the stream is closed, or nil.
+func (m *MultiSubscriber) Client(source string) *Client {
+	return m.clients[source]
+}
+
+// Events connects to all streams and returns the channel of the received
+// events. The streams reconnect independently; the channel is closed when all
+// of them are closed. The first connection error of any stream is returned
+// and no stream is left connected then.
+func (m *MultiSubscriber) Events(ctx context.Context) (<-chan SourceEvent, error) {
+	sources := make([]string, 0, len(m.clients))
+	for source := range m.clients {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	ctx, cancel := context.WithCancel(ctx)
+	streams := make(map[string]<-chan Event, len(sources))
+	for _, source := range sources {
+		events, err := m.clients[source].Events(ctx)
+		if err != nil {
+			cancel()
+			for _, events := range streams {
+				for range events {
+				}
+			}
+			return nil, fmt.Errorf("sse: source %s: %w", source, err)
+		}
+		streams[source] = events
+	}
+
+	out := make(chan SourceEvent)
+	var wg sync.WaitGroup
+	for source, events := range streams {
+		wg.Add(1)
+		go func(source string, events <-chan Event) {
+			defer wg.Done()
+			for e := range events {
+				select {
+				case out <- SourceEvent{Source: source, Event: e}:
+				case <-ctx.Done():
+				}
+			}
+		}(source, events)
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+		close(out)
+	}()
+	return out, nil
+}
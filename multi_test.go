@@ -0,0 +1,48 @@
+package sse
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMultiSubscriber(t *testing.T) {
+	orders, users := New(), New()
+	tsOrders, tsUsers := httptest.NewServer(orders), httptest.NewServer(users)
+	defer tsOrders.Close()
+	defer tsUsers.Close()
+	defer orders.Close()
+	defer users.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewMultiSubscriber(map[string]string{"orders": tsOrders.URL, "users": tsUsers.URL})
+	events, err := m.Events(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitConnected(t, orders, 1)
+	waitConnected(t, users, 1)
+
+	go orders.Send(Event{Data: "paid"})
+	if e := <-events; e.Source != "orders" || e.Data != "paid" {
+		t.Errorf("event %+v", e)
+	}
+	go users.Send(Event{Data: "joined"})
+	if e := <-events; e.Source != "users" || e.Data != "joined" {
+		t.Errorf("event %+v", e)
+	}
+
+	cancel()
+	for range events {
+	}
+	if err := m.Client("orders").Err(); err != context.Canceled {
+		t.Errorf("error %v", err)
+	}
+
+	bad := NewMultiSubscriber(map[string]string{"orders": tsOrders.URL, "users": tsOrders.URL + "/\x00"})
+	if _, err := bad.Events(context.Background()); err == nil {
+		t.Error("no connection error")
+	}
+	waitConnected(t, orders, 0)
+}
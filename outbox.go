@@ -0,0 +1,137 @@
+package sse
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Source feeds the events from the outside system to the publisher until the
+// context is done.
+type Source interface {
+	Run(ctx context.Context, p Publisher) error
+}
+
+// Outbox is the Source polling the outbox table of the database, so the rows
+// written by the application transactions are pushed to the browsers without
+// the message broker:
+//
+//	outbox := &sse.Outbox{
+//		DB:     db,
+//		Query:  "SELECT id, name, payload FROM outbox WHERE id > $1 ORDER BY id LIMIT 100",
+//		Cursor: lastID, // loaded on start
+//		OnCursor: func(cursor string) error {
+//			_, err := db.Exec("UPDATE outbox_cursor SET id = $1", cursor)
+//			return err
+//		},
+//	}
+//	go outbox.Run(ctx, s)
+//
+// The query gets the cursor as the only argument and returns the new rows in
+// order with three columns: the cursor value, also used as the event
+// identifier, the event name (may be NULL or empty) and the data. The cursor
+// is advanced after the row is published, so the rows are pushed at least
+// once; with the persisted cursor and the event identifiers the clients skip
+// the duplicates after the restart.
+type Outbox struct {
+	DB       *sql.DB
+	Query    string        // new rows after the cursor
+	Cursor   string        // cursor to start from, such as the persisted one
+	Interval time.Duration // polling interval, one second if zero
+	QoS      QoS           // delivery guarantee level of the events
+	Clock    Clock         // time source of the polling, the system clock if nil
+
+	// OnCursor, if set, is called with the cursor after the rows are
+	// published, such as to persist it; the polling stops with its error.
+	OnCursor func(cursor string) error
+	// OnError, if set, is called with the query errors; the query is retried
+	// after the interval.
+	OnError func(error)
+}
+
+var _ Source = (*Outbox)(nil)
+
+// Run polls the outbox and publishes the new rows until the context is done
+// or the cursor callback fails. The publishers implementing SendContext, as
+// the Server does, stop the polling with the publishing error, as the row is
+// not delivered; the cursor stays before it then.
+func (o *Outbox) Run(ctx context.Context, p Publisher) error {
+	interval := o.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := clockOrSystem(o.Clock).NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := o.poll(ctx, p)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			continue // more rows may be waiting
+		}
+		select {
+		case <-ticker.C():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// poll publishes the new rows and returns their number. The query errors are
+// reported and not returned.
+func (o *Outbox) poll(ctx context.Context, p Publisher) (int, error) {
+	rows, err := o.DB.QueryContext(ctx, o.Query, o.Cursor)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		o.report(err)
+		return 0, nil
+	}
+	defer rows.Close()
+	sender, _ := p.(contextSender)
+	var (
+		n       int
+		sendErr error // error of the row not delivered
+	)
+	for rows.Next() {
+		var (
+			cursor, data string
+			name         sql.NullString
+		)
+		if err := rows.Scan(&cursor, &name, &data); err != nil {
+			o.report(err)
+			break
+		}
+		e := Event{ID: cursor, Name: name.String, Data: data, QoS: o.QoS}
+		if sender != nil {
+			if sendErr = sender.SendContext(ctx, e); sendErr != nil {
+				break
+			}
+		} else {
+			p.Send(e)
+		}
+		o.Cursor = cursor
+		n++
+	}
+	if err := rows.Err(); err != nil && ctx.Err() == nil {
+		o.report(err)
+	}
+	if n > 0 && o.OnCursor != nil {
+		if err := o.OnCursor(o.Cursor); err != nil {
+			return n, err
+		}
+	}
+	if sendErr != nil {
+		return n, sendErr
+	}
+	return n, ctx.Err()
+}
+
+// report calls the error callback, if set.
+func (o *Outbox) report(err error) {
+	if o.OnError != nil {
+		o.OnError(err)
+	}
+}
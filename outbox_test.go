@@ -0,0 +1,187 @@
+package sse
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// outboxTable is the table of the fake outbox driver: the rows with the
+// identifiers after the argument are selected.
+type outboxTable struct {
+	mu   sync.Mutex
+	rows [][]driver.Value // id, name, data
+	fail error            // error of the next query
+}
+
+func (t *outboxTable) insert(name interface{}, data string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rows = append(t.rows, []driver.Value{int64(len(t.rows) + 1), name, data})
+}
+
+func (t *outboxTable) Open(string) (driver.Conn, error) { return outboxConn{t}, nil }
+
+// outboxConnector opens the connections to the table without the driver
+// registration, so the tests can run repeatedly.
+type outboxConnector struct{ table *outboxTable }
+
+func (c outboxConnector) Connect(context.Context) (driver.Conn, error) { return outboxConn(c), nil }
+func (c outboxConnector) Driver() driver.Driver                        { return c.table }
+
+type outboxConn struct{ table *outboxTable }
+
+func (c outboxConn) Prepare(string) (driver.Stmt, error) { return outboxStmt(c), nil }
+func (c outboxConn) Close() error                        { return nil }
+func (c outboxConn) Begin() (driver.Tx, error)           { return nil, errors.New("no transactions") }
+
+type outboxStmt struct{ table *outboxTable }
+
+func (s outboxStmt) Close() error                               { return nil }
+func (s outboxStmt) NumInput() int                              { return 1 }
+func (s outboxStmt) Exec([]driver.Value) (driver.Result, error) { return nil, errors.New("no exec") }
+
+func (s outboxStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+	if err := s.table.fail; err != nil {
+		s.table.fail = nil
+		return nil, err
+	}
+	after, _ := strconv.ParseInt(args[0].(string), 10, 64)
+	rows := &outboxRows{}
+	for _, row := range s.table.rows {
+		if row[0].(int64) > after {
+			rows.rows = append(rows.rows, row)
+		}
+	}
+	return rows, nil
+}
+
+type outboxRows struct{ rows [][]driver.Value }
+
+func (r *outboxRows) Columns() []string { return []string{"id", "name", "data"} }
+func (r *outboxRows) Close() error      { return nil }
+
+func (r *outboxRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestOutbox(t *testing.T) {
+	table := new(outboxTable)
+	db := sql.OpenDB(outboxConnector{table})
+	defer db.Close()
+	table.insert("order", "1")
+	table.insert(nil, "2")
+	table.fail = errors.New("connection lost")
+
+	mock := &outboxPublisher{sent: make(chan Event, 10)}
+	cursors := make(chan string, 10)
+	var reported error
+	outbox := &Outbox{
+		DB:       db,
+		Query:    "SELECT id, name, data FROM outbox WHERE id > ?",
+		Cursor:   "0",
+		Interval: 5 * time.Millisecond,
+		OnCursor: func(cursor string) error { cursors <- cursor; return nil },
+		OnError:  func(err error) { reported = err },
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- outbox.Run(ctx, mock) }()
+
+	for _, want := range []Event{{ID: "1", Name: "order", Data: "1"}, {ID: "2", Data: "2"}} {
+		if e := <-mock.sent; e != want {
+			t.Errorf("event %+v, want %+v", e, want)
+		}
+	}
+	if cursor := <-cursors; cursor != "2" {
+		t.Errorf("cursor %q", cursor)
+	}
+	table.insert("order", "3")
+	if e := <-mock.sent; e.ID != "3" {
+		t.Errorf("new row event %+v", e)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("run error %v", err)
+	}
+	if reported == nil || reported.Error() != "connection lost" {
+		t.Errorf("reported %v", reported)
+	}
+
+	// the polling interval is timed by the clock
+	clock := &tickClock{ticks: make(chan time.Time)}
+	outbox = &Outbox{DB: db, Query: "SELECT", Cursor: "3", Interval: time.Hour, Clock: clock}
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go func() { done <- outbox.Run(ctx, mock) }()
+	tick := func() {
+		select {
+		case clock.ticks <- time.Now(): // received after the poll
+		case <-time.After(time.Second):
+			t.Fatal("polling is not timed by the clock")
+		}
+	}
+	tick()
+	table.insert("order", "4")
+	tick()
+	tick()
+	if e := <-mock.sent; e.ID != "4" {
+		t.Errorf("polled event %+v", e)
+	}
+	cancel()
+	<-done
+
+	// the failed delivery stops the polling before the row
+	outbox = &Outbox{DB: db, Query: "SELECT", Cursor: "1"}
+	failing := errors.New("not delivered")
+	if err := outbox.Run(context.Background(), failingSender{failing}); err != failing || outbox.Cursor != "1" {
+		t.Errorf("run error %v, cursor %q", err, outbox.Cursor)
+	}
+}
+
+// tickClock is the system clock with the ticks sent by the test.
+type tickClock struct {
+	systemClock
+	ticks chan time.Time
+}
+
+func (c *tickClock) NewTicker(time.Duration) Ticker { return tickTicker(c.ticks) }
+
+type tickTicker chan time.Time
+
+func (t tickTicker) C() <-chan time.Time { return t }
+func (t tickTicker) Stop()               {}
+
+// outboxPublisher records the sent events.
+type outboxPublisher struct {
+	Publisher
+	sent chan Event
+}
+
+func (p *outboxPublisher) Send(e Event) { p.sent <- e }
+
+// failingSender fails all sends.
+type failingSender struct {
+	err error
+}
+
+func (f failingSender) Send(Event)                               {}
+func (f failingSender) Publish(string, ...EventOption) error     { return f.err }
+func (f failingSender) Event(string, string, interface{}) error  { return f.err }
+func (f failingSender) Comment(string)                           {}
+func (f failingSender) Retry(time.Duration)                      {}
+func (f failingSender) Close()                                   {}
+func (f failingSender) SendContext(context.Context, Event) error { return f.err }
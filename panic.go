@@ -0,0 +1,20 @@
+package sse
+
+// WithOnPanic sets the function called with the recovered value when a panic
+// occurs while delivering the event to the client or serving its connection,
+// including the panics in the user hooks. The panic of one connection does not
+// stop the delivery to others. Without the hook the panic is propagated after
+// the server state is restored.
+func WithOnPanic(fn func(v interface{}, info ClientInfo)) Option {
+	return func(s *Server) {
+		s.onPanic = fn
+	}
+}
+
+// panicked reports the recovered panic to the hook or propagates it.
+func (s *Server) panicked(v interface{}, info ClientInfo) {
+	if s.onPanic == nil {
+		panic(v)
+	}
+	s.onPanic(v, info)
+}
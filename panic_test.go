@@ -0,0 +1,57 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOnPanic(t *testing.T) {
+	panics := make(chan interface{}, 2)
+	s := New(
+		WithClientID(func(r *http.Request) string { return r.Header.Get("X-User") }),
+		WithScopes(func(r *http.Request) []string {
+			if r.Header.Get("X-User") == "broken" {
+				panic("broken scopes")
+			}
+			return nil
+		}),
+		WithOnPanic(func(v interface{}, _ ClientInfo) { panics <- v }),
+	)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("X-User", "broken")
+	if res, err := ts.Client().Do(req); err == nil {
+		res.Body.Close()
+	}
+	if v := <-panics; v != "broken scopes" {
+		t.Errorf("recovered %v", v)
+	}
+
+	bad := connect(t, ts, http.Header{"X-User": {"bad"}})
+	good := connect(t, ts, http.Header{"X-User": {"good"}})
+	waitConnected(t, s, 2)
+
+	go s.send(":ping\n", func(c *client) bool {
+		if c.info.ID == "bad" {
+			panic("bad client")
+		}
+		return true
+	})
+	if msg := readMessage(t, good); msg != ":ping\n" {
+		t.Errorf("good client got %q", msg)
+	}
+	if v := <-panics; v != "bad client" {
+		t.Errorf("recovered %v", v)
+	}
+
+	// the server is not locked after the panic
+	go s.Send(Event{Data: "after"})
+	if msg := readMessage(t, bad); msg != "data: after\n" {
+		t.Errorf("bad client got %q", msg)
+	}
+}
@@ -0,0 +1,167 @@
+package sse
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONDocument tracks the JSON document and broadcasts its changes as
+// RFC 6902 JSON Patch events named "patch". Each new connection, including
+// the reconnected ones, first receives the full document as the event named
+// "document", so the clients only need to apply the patches to it.
+type JSONDocument struct {
+	state *StateStream
+	doc   interface{} // current decoded document
+	raw   string      // current encoded document
+}
+
+// PatchOperation is the RFC 6902 JSON Patch operation.
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON implements json.Marshaler interface. The value is omitted for
+// the remove operation only, as null is the valid value of others.
+func (op PatchOperation) MarshalJSON() ([]byte, error) {
+	if op.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{op.Op, op.Path})
+	}
+	type operation PatchOperation // without the MarshalJSON method
+	return json.Marshal(operation(op))
+}
+
+// NewJSONDocument returns the tracked document with the given initial value
+// published by the server. The server should not be used to publish other
+// events.
+func NewJSONDocument(s *Server, v interface{}) (*JSONDocument, error) {
+	d := new(JSONDocument)
+	if err := d.set(v); err != nil {
+		return nil, err
+	}
+	d.state = NewStateStream(s, func() (Event, error) {
+		return Event{Name: "document", Data: d.raw}, nil
+	})
+	return d, nil
+}
+
+// Update replaces the document with the new version and broadcasts the patch
+// with the changes. Nothing is sent if the document is not changed.
+func (d *JSONDocument) Update(v interface{}) error {
+	var err error
+	d.state.Update(func() (Event, bool) {
+		old := d.doc
+		if err = d.set(v); err != nil {
+			return Event{}, false
+		}
+		ops := Diff(old, d.doc)
+		if len(ops) == 0 {
+			return Event{}, false
+		}
+		var data []byte
+		if data, err = json.Marshal(ops); err != nil {
+			return Event{}, false
+		}
+		return Event{Name: "patch", Data: string(data)}, true
+	})
+	return err
+}
+
+// ServeHTTP implements http.Handler interface.
+func (d *JSONDocument) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.state.ServeHTTP(w, r)
+}
+
+// set replaces the current document.
+func (d *JSONDocument) set(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return err
+	}
+	d.doc, d.raw = doc, string(data)
+	return nil
+}
+
+// Diff returns the JSON Patch operations transforming the decoded JSON value a
+// into b.
+func Diff(a, b interface{}) []PatchOperation {
+	return appendDiff(nil, "", a, b)
+}
+
+// pointerEscaper escapes the JSON Pointer reference token.
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// appendDiff appends the operations transforming a into b at the path.
+func appendDiff(ops []PatchOperation, path string, a, b interface{}) []PatchOperation {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(a)+len(b))
+		for k := range a {
+			keys = append(keys, k)
+		}
+		for k := range b {
+			if _, ok := a[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := path + "/" + pointerEscaper.Replace(k)
+			av, inA := a[k]
+			bv, inB := b[k]
+			switch {
+			case !inB:
+				ops = append(ops, PatchOperation{Op: "remove", Path: p})
+			case !inA:
+				ops = append(ops, PatchOperation{Op: "add", Path: p, Value: bv})
+			default:
+				ops = appendDiff(ops, p, av, bv)
+			}
+		}
+		return ops
+
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		n := len(a)
+		if len(b) < n {
+			n = len(b)
+		}
+		for i := 0; i < n; i++ {
+			ops = appendDiff(ops, path+"/"+strconv.Itoa(i), a[i], b[i])
+		}
+		for i := len(a) - 1; i >= n; i-- { // remove from the end
+			ops = append(ops, PatchOperation{Op: "remove", Path: path + "/" + strconv.Itoa(i)})
+		}
+		for i := n; i < len(b); i++ {
+			ops = append(ops, PatchOperation{Op: "add", Path: path + "/-", Value: b[i]})
+		}
+		return ops
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		ops = append(ops, PatchOperation{Op: "replace", Path: path, Value: b})
+	}
+	return ops
+}
@@ -0,0 +1,63 @@
+package sse
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	decode := func(s string) interface{} {
+		var v interface{}
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	for _, test := range []struct{ a, b, patch string }{
+		{`{"a":1}`, `{"a":1}`, `null`},
+		{`{"a":1,"b":2}`, `{"a":3,"c":null}`,
+			`[{"op":"replace","path":"/a","value":3},{"op":"remove","path":"/b"},{"op":"add","path":"/c","value":null}]`},
+		{`{"a/b":{"c~":[1,2,3]}}`, `{"a/b":{"c~":[1,5]}}`,
+			`[{"op":"replace","path":"/a~1b/c~0/1","value":5},{"op":"remove","path":"/a~1b/c~0/2"}]`},
+		{`[1]`, `[1,{"x":true}]`, `[{"op":"add","path":"/-","value":{"x":true}}]`},
+		{`{"a":[1]}`, `{"a":"text"}`, `[{"op":"replace","path":"/a","value":"text"}]`},
+		{`1`, `"root"`, `[{"op":"replace","path":"","value":"root"}]`},
+	} {
+		data, err := json.Marshal(Diff(decode(test.a), decode(test.b)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != test.patch {
+			t.Errorf("diff %s %s:\n got %s\nwant %s", test.a, test.b, data, test.patch)
+		}
+	}
+}
+
+func TestJSONDocument(t *testing.T) {
+	s := New()
+	doc, err := NewJSONDocument(s, map[string]interface{}{"count": 1, "name": "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(doc)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil)
+	if msg := readMessage(t, r); msg != "event: document\ndata: {\"count\":1,\"name\":\"test\"}\nid: 0\n" {
+		t.Errorf("document %q", msg)
+	}
+
+	go func() {
+		_ = doc.Update(map[string]interface{}{"count": 1, "name": "test"}) // not changed
+		_ = doc.Update(map[string]interface{}{"count": 2, "name": "test"})
+	}()
+	if msg := readMessage(t, r); msg != "event: patch\ndata: [{\"op\":\"replace\",\"path\":\"/count\",\"value\":2}]\nid: 1\n" {
+		t.Errorf("patch %q", msg)
+	}
+
+	if err := doc.Update(func() {}); err == nil {
+		t.Error("not encodable document accepted")
+	}
+}
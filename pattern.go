@@ -0,0 +1,103 @@
+package sse
+
+import "strings"
+
+// matchChannel reports whether the channel matches the subscription pattern.
+// The channels are hierarchical with the dot separated segments: the "*"
+// segment of the pattern matches any one segment, and the "#" at the end of
+// the pattern matches the rest of the channel, so "orders.*" matches
+// "orders.new" and "metrics.host-#" matches "metrics.host-1.cpu".
+func matchChannel(pattern, channel string) bool {
+	for {
+		seg, rest, more := strings.Cut(pattern, ".")
+		if !more && strings.HasSuffix(seg, "#") {
+			return strings.HasPrefix(channel, seg[:len(seg)-1])
+		}
+		name, next, ok := strings.Cut(channel, ".")
+		if seg != "*" && seg != name {
+			return false
+		}
+		if !more || !ok {
+			return more == ok
+		}
+		pattern, channel = rest, next
+	}
+}
+
+// subscribed reports whether the client subscribes to the channel.
+func (c *client) subscribed(channel string) bool {
+	if c.channels == nil {
+		return c.info.Subscribed(channel)
+	}
+	return c.channels.match(channel)
+}
+
+// channelTrie is the trie of the subscription patterns of the client,
+// matching the channel in time proportional to its segments rather than the
+// number of the patterns.
+type channelTrie struct {
+	children map[string]*channelTrie // exact segments
+	any      *channelTrie            // "*" segment
+	prefixes []string                // rest of the channel prefixes of "#"
+	end      bool                    // a pattern ends here
+}
+
+// newChannelTrie returns the trie of the patterns.
+func newChannelTrie(patterns []string) *channelTrie {
+	t := new(channelTrie)
+	for _, pattern := range patterns {
+		t.add(pattern)
+	}
+	return t
+}
+
+// add adds the pattern to the trie.
+func (t *channelTrie) add(pattern string) {
+	node := t
+	for {
+		seg, rest, more := strings.Cut(pattern, ".")
+		if !more && strings.HasSuffix(seg, "#") {
+			node.prefixes = append(node.prefixes, seg[:len(seg)-1])
+			return
+		}
+		next := node.any
+		if seg != "*" {
+			next = node.children[seg]
+		}
+		if next == nil {
+			next = new(channelTrie)
+			if seg == "*" {
+				node.any = next
+			} else {
+				if node.children == nil {
+					node.children = make(map[string]*channelTrie)
+				}
+				node.children[seg] = next
+			}
+		}
+		if !more {
+			next.end = true
+			return
+		}
+		node, pattern = next, rest
+	}
+}
+
+// match reports whether the channel matches any pattern of the trie.
+func (t *channelTrie) match(channel string) bool {
+	for _, prefix := range t.prefixes {
+		if strings.HasPrefix(channel, prefix) {
+			return true
+		}
+	}
+	seg, rest, more := strings.Cut(channel, ".")
+	for _, next := range [...]*channelTrie{t.children[seg], t.any} {
+		if next == nil {
+			continue
+		}
+		if more && next.match(rest) || !more && next.end {
+			return true
+		}
+	}
+	return false
+}
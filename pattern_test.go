@@ -0,0 +1,83 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestChannelPatterns(t *testing.T) {
+	for _, test := range []struct {
+		pattern, channel string
+		want             bool
+	}{
+		{"orders", "orders", true},
+		{"orders", "orders.new", false},
+		{"orders.*", "orders.new", true},
+		{"orders.*", "orders", false},
+		{"orders.*", "orders.new.eu", false},
+		{"*.new", "orders.new", true},
+		{"*.new", "orders.paid", false},
+		{"orders.#", "orders.new.eu", true},
+		{"orders.#", "users.new", false},
+		{"metrics.host-#", "metrics.host-1", true},
+		{"metrics.host-#", "metrics.host-1.cpu", true},
+		{"metrics.host-#", "metrics.disk", false},
+		{"#", "anything.at.all", true},
+		{"a.#.b", "a.#.b", true}, // not at the end, literal
+		{"a.#.b", "a.x.b", false},
+	} {
+		if got := matchChannel(test.pattern, test.channel); got != test.want {
+			t.Errorf("%q matches %q: %v", test.pattern, test.channel, got)
+		}
+		if got := newChannelTrie([]string{test.pattern}).match(test.channel); got != test.want {
+			t.Errorf("trie of %q matches %q: %v", test.pattern, test.channel, got)
+		}
+	}
+
+	trie := newChannelTrie([]string{"orders.*", "orders.eu.#", "users.alice"})
+	for channel, want := range map[string]bool{
+		"orders.new":      true,
+		"orders.eu.new.1": true,
+		"orders.us.new":   false,
+		"users.alice":     true,
+		"users.bob":       false,
+	} {
+		if got := trie.match(channel); got != want {
+			t.Errorf("trie matches %q: %v", channel, got)
+		}
+	}
+}
+
+func TestChannelPatternsDelivery(t *testing.T) {
+	s := New(WithChannels(func(r *http.Request) []string {
+		return []string{"orders.*"}
+	}))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	s.Send(Event{Data: "deep", Channel: "orders.new.eu"})
+	s.Send(Event{Data: "other", Channel: "users.new"})
+	s.Send(Event{Data: "new", Channel: "orders.new"})
+	if msg := readMessage(t, r); msg != "data: new\n" {
+		t.Errorf("received %q", msg)
+	}
+}
+
+func BenchmarkChannelTrie(b *testing.B) {
+	patterns := make([]string, 100)
+	for i := range patterns {
+		patterns[i] = "metrics.host-" + strconv.Itoa(i) + ".*"
+	}
+	trie := newChannelTrie(patterns)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if !trie.match("metrics.host-99.cpu") {
+			b.Fatal("not matched")
+		}
+	}
+}
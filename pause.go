@@ -0,0 +1,105 @@
+package sse
+
+import "context"
+
+// WithPauseBuffer sets the number of the events read and held while the
+// client is paused (see Client.Pause). When the buffer is full, the reading
+// stops too, letting the TCP backpressure apply. By default the reading stops
+// at once.
+func WithPauseBuffer(n int) ClientOption {
+	return func(c *Client) {
+		c.pauseBuffer = n
+	}
+}
+
+// Pause stops sending the events to the channel without closing the
+// connection, such as while the consumer temporarily can not process them.
+// The events are held in the pause buffer (see WithPauseBuffer) and then not
+// read at all.
+func (c *Client) Pause() {
+	c.setPaused(true)
+}
+
+// Resume continues sending the events to the channel, the held ones first.
+func (c *Client) Resume() {
+	c.setPaused(false)
+}
+
+// Paused reports whether the client is paused.
+func (c *Client) Paused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+// setPaused switches the pause and wakes up the dispatch.
+func (c *Client) setPaused(paused bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused == paused {
+		return
+	}
+	c.paused = paused
+	if c.pauseChanged != nil {
+		close(c.pauseChanged)
+		c.pauseChanged = nil
+	}
+}
+
+// pause returns whether the client is paused and the channel closed when it
+// is switched.
+func (c *Client) pause() (bool, <-chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pauseChanged == nil {
+		c.pauseChanged = make(chan struct{})
+	}
+	return c.paused, c.pauseChanged
+}
+
+// dispatch sends the received events to the channel, holding them while the
+// client is paused, until the received ones are done or the context is done.
+// The resume point is saved after each event is taken from the channel.
+func (c *Client) dispatch(ctx context.Context, in <-chan received, events chan<- Event) error {
+	var held []received
+	for {
+		paused, changed := c.pause()
+		if in == nil && len(held) == 0 {
+			return nil
+		}
+		var (
+			recv <-chan received
+			out  chan<- Event
+			next Event
+		)
+		if paused {
+			if len(held) < c.pauseBuffer {
+				recv = in
+			}
+		} else if len(held) == 0 {
+			recv = in
+		} else {
+			out, next = events, held[0].event
+		}
+
+		select {
+		case r, ok := <-recv:
+			if !ok {
+				in = nil
+				continue
+			}
+			held = append(held, r)
+		case out <- next:
+			lastID := held[0].lastID
+			held = held[1:]
+			if c.resume != nil {
+				if err := c.resume.Save(lastID); err != nil {
+					return err
+				}
+			}
+		case <-changed:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package sse
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientPause(t *testing.T) {
+	s := New(WithBufferSize(10))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := NewClient(ts.URL, WithPauseBuffer(1))
+	events, err := c.Events(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitConnected(t, s, 1)
+
+	c.Pause()
+	if !c.Paused() {
+		t.Error("not paused")
+	}
+	s.Send(Event{Data: "held"})
+	s.Send(Event{Data: "queued"})
+	select {
+	case e := <-events:
+		t.Fatalf("dispatched while paused %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	c.Resume()
+	for _, want := range []string{"held", "queued"} {
+		select {
+		case e := <-events:
+			if e.Data != want {
+				t.Errorf("event %q, want %q", e.Data, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %q event after resume", want)
+		}
+	}
+}
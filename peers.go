@@ -0,0 +1,187 @@
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// maxPeerMessage is the maximum size of the message relayed to the peers.
+const maxPeerMessage = 208 << 10
+
+// Peers relays the events between the processes on the same host, such as
+// the prefork workers sharing the listening port, so the clients connected to
+// any of them receive every event without the external message bus. Each
+// process joins the peers with the same directory and publishes with the
+// Peers instead of the Server:
+//
+//	peers, err := sse.JoinPeers(s, "/run/myapp/sse")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer peers.Close()
+//	peers.Send(sse.Event{Data: "hello"}) // the clients of all workers get it
+//
+// The messages are the datagrams of the unix sockets in the directory, one per
+// process, so the encoded event must fit 208 KiB. The sockets of the crashed
+// processes are removed by the peers on the first send.
+type Peers struct {
+	server *Server
+	dir    string
+	path   string // own socket path
+	conn   *net.UnixConn
+	once   sync.Once
+	done   chan struct{} // closed when the receiving is stopped
+}
+
+var _ Publisher = (*Peers)(nil)
+
+// peerMessage is the message relayed to the peers.
+type peerMessage struct {
+	Event   *Event         `json:"e,omitempty"`
+	Comment *string        `json:"c,omitempty"`
+	Retry   *time.Duration `json:"r,omitempty"`
+}
+
+// JoinPeers creates the socket of the process in the directory and relays
+// the messages received from the other processes to the clients of the
+// server. The directory is created if it does not exist.
+func JoinPeers(s *Server, dir string) (*Peers, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, strconv.Itoa(os.Getpid())+"-"+newClientID()[:8]+".sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	p := &Peers{server: s, dir: dir, path: path, conn: conn, done: make(chan struct{})}
+	go p.receive()
+	return p, nil
+}
+
+// receive delivers the messages of the peers to the clients of the server
+// until the socket is closed.
+func (p *Peers) receive() {
+	defer close(p.done)
+	buf := make([]byte, maxPeerMessage)
+	for {
+		n, _, err := p.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		var msg peerMessage
+		if err := json.Unmarshal(buf[:n], &msg); err != nil {
+			p.server.onWriteError(err, ClientInfo{})
+			continue
+		}
+		p.deliver(msg)
+	}
+}
+
+// deliver sends the message to the clients of the server.
+func (p *Peers) deliver(msg peerMessage) {
+	switch {
+	case msg.Event != nil:
+		p.server.Send(*msg.Event)
+	case msg.Comment != nil:
+		p.server.Comment(*msg.Comment)
+	case msg.Retry != nil:
+		p.server.Retry(*msg.Retry)
+	}
+}
+
+// relay sends the message to the clients of the server and to all peers. The
+// relay errors are reported to the WithOnError hook of the server and the
+// first one is returned, or ErrClosed after Close.
+func (p *Peers) relay(msg peerMessage) error {
+	select {
+	case <-p.done:
+		return ErrClosed
+	default:
+	}
+	p.deliver(msg)
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if len(data) > maxPeerMessage {
+		p.server.onWriteError(ErrTooLarge, ClientInfo{})
+		return ErrTooLarge
+	}
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		p.server.onWriteError(err, ClientInfo{})
+		return err
+	}
+	var first error
+	for _, entry := range entries {
+		path := filepath.Join(p.dir, entry.Name())
+		if path == p.path || !strings.HasSuffix(entry.Name(), ".sock") {
+			continue
+		}
+		_, err := p.conn.WriteTo(data, &net.UnixAddr{Name: path, Net: "unixgram"})
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			_ = os.Remove(path) // the peer has crashed
+			continue
+		}
+		if err != nil {
+			p.server.onWriteError(err, ClientInfo{})
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+// Send sends the event to the clients of the server and all peers.
+func (p *Peers) Send(e Event) {
+	_ = p.relay(peerMessage{Event: &e})
+}
+
+// Publish sends the event with the given data and options to the clients of
+// the server and all peers.
+func (p *Peers) Publish(data string, opts ...EventOption) error {
+	e := NewEventData(data, opts...)
+	return p.relay(peerMessage{Event: &e})
+}
+
+// Event sends the event with the data encoded as JSON to the clients of the
+// server and all peers.
+func (p *Peers) Event(id, name string, v interface{}) error {
+	e, err := NewEvent(id, name, v)
+	if err != nil {
+		return err
+	}
+	return p.relay(peerMessage{Event: &e})
+}
+
+// Comment sends the comment to the clients of the server and all peers.
+func (p *Peers) Comment(text string) {
+	_ = p.relay(peerMessage{Comment: &text})
+}
+
+// Retry sends the reconnection delay to the clients of the server and all
+// peers.
+func (p *Peers) Retry(d time.Duration) {
+	_ = p.relay(peerMessage{Retry: &d})
+}
+
+// Close leaves the peers, removing the socket of the process, and closes the
+// server.
+func (p *Peers) Close() {
+	p.once.Do(func() {
+		p.conn.Close()
+		<-p.done
+		_ = os.Remove(p.path)
+	})
+	p.server.Close()
+}
@@ -0,0 +1,70 @@
+package sse
+
+import (
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPeers(t *testing.T) {
+	dir := t.TempDir()
+	s1, s2 := New(), New()
+	ts1, ts2 := httptest.NewServer(s1), httptest.NewServer(s2)
+	defer ts1.Close()
+	defer ts2.Close()
+	p1, err := JoinPeers(s1, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p1.Close()
+	p2, err := JoinPeers(s2, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p2.Close()
+
+	// the socket of the crashed peer is left
+	dead := filepath.Join(dir, "1-dead.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: dead, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	r1, r2 := connect(t, ts1, nil), connect(t, ts2, nil)
+	waitConnected(t, s1, 1)
+	waitConnected(t, s2, 1)
+
+	p1.Send(Event{Name: "order", Data: "42"})
+	if msg := readMessage(t, r1); msg != "event: order\ndata: 42\n" {
+		t.Errorf("local client %q", msg)
+	}
+	if msg := readMessage(t, r2); msg != "event: order\ndata: 42\n" {
+		t.Errorf("peer client %q", msg)
+	}
+	if _, err := os.Stat(dead); !os.IsNotExist(err) {
+		t.Error("socket of the crashed peer is not removed:", err)
+	}
+
+	p2.Comment("from peer")
+	if msg := readMessage(t, r1); msg != ": from peer\n" {
+		t.Errorf("relayed comment %q", msg)
+	}
+	if err := p2.Publish("x", WithName("big")); err != nil {
+		t.Fatal(err)
+	}
+	if msg := readMessage(t, r1); msg != "event: big\ndata: x\n" {
+		t.Errorf("relayed publish %q", msg)
+	}
+
+	p2.Close()
+	if _, err := os.Stat(p2.path); !os.IsNotExist(err) {
+		t.Error("socket is not removed on close:", err)
+	}
+	waitConnected(t, s2, 0) // the server is closed
+	if err := p2.Publish("x"); err != ErrClosed {
+		t.Errorf("publish after close error %v", err)
+	}
+}
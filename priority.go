@@ -0,0 +1,33 @@
+package sse
+
+import "time"
+
+// Priority is the delivery priority of the event when the client queue is
+// under pressure (see WithBufferSize). The priorities are ordered from low to
+// high, the zero value is PriorityNormal.
+type Priority int
+
+const (
+	// PriorityLow events, such as typing indicators, are dropped once the
+	// client queue is half full, leaving the room for the other events.
+	PriorityLow Priority = iota - 1
+	// PriorityNormal events are queued according to the slow client policy.
+	PriorityNormal
+	// PriorityHigh events, such as payment confirmations, are not dropped
+	// silently: with the SlowClientDrop policy the sender waits up to
+	// maxPriorityWait for the room in the full queue and then disconnects the
+	// client, which gets the event replayed on reconnection if the event is
+	// stored (see WithEventStore). With the SlowClientDisconnect policy the
+	// slow client is disconnected as for the other events.
+	PriorityHigh
+)
+
+// maxPriorityWait is the maximum time of waiting for the room in the full
+// client queue for the high priority event. The fan-out to the other clients
+// waits too, so the time is short.
+const maxPriorityWait = 100 * time.Millisecond
+
+// underPressure reports whether the queue of the client is at least half full.
+func (c *client) underPressure() bool {
+	return cap(c.messages) > 0 && len(c.messages)*2 >= cap(c.messages)
+}
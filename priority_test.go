@@ -0,0 +1,60 @@
+package sse
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPriority(t *testing.T) {
+	s := New(WithBufferSize(4), WithSlowClientPolicy(SlowClientDrop))
+	c := &client{messages: make(chan message, 4), done: make(chan struct{})}
+	defer c.disconnect()
+
+	for i, want := range []struct {
+		priority Priority
+		err      error
+	}{
+		{PriorityLow, nil},
+		{PriorityNormal, nil},
+		{PriorityLow, errDropped}, // half full
+		{PriorityNormal, nil},
+		{PriorityHigh, nil},
+		{PriorityNormal, errDropped}, // full
+	} {
+		if err := s.deliver(context.Background(), c, "data", Event{Priority: want.priority}); err != want.err {
+			t.Errorf("%d: error %v, want %v", i, err, want.err)
+		}
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		<-c.messages
+	}()
+	if err := s.deliver(context.Background(), c, "urgent", Event{Priority: PriorityHigh}); err != nil {
+		t.Error("high priority:", err)
+	}
+	if len(c.messages) != 4 {
+		t.Errorf("queued %d", len(c.messages))
+	}
+
+	// the queue stays full
+	if err := s.deliver(context.Background(), c, "urgent", Event{Priority: PriorityHigh}); err != ErrSlowClient {
+		t.Error("high priority to slow client:", err)
+	}
+	select {
+	case <-c.done:
+	default:
+		t.Error("slow client is not disconnected")
+	}
+}
+
+func TestPriorityOrder(t *testing.T) {
+	var zero Priority
+	if zero != PriorityNormal {
+		t.Errorf("zero priority %d, want PriorityNormal", zero)
+	}
+	if !(PriorityLow < PriorityNormal && PriorityNormal < PriorityHigh) {
+		t.Errorf("priorities %d, %d, %d not ordered", PriorityLow, PriorityNormal, PriorityHigh)
+	}
+}
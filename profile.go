@@ -0,0 +1,83 @@
+package sse
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// The compatibility profiles bundle the options making the streams work
+// through the intermediaries and the clients with the known quirks. They are
+// the usual options, so they combine with each other and the later options
+// override them:
+//
+//	s := sse.New(sse.ProfileNginx, sse.WithHeartbeat(10*time.Second))
+var (
+	// ProfileNginx disables the response buffering of the nginx proxy and
+	// sends the heartbeats within its default 60 seconds read timeout.
+	ProfileNginx = profile(
+		WithStreamHeader("X-Accel-Buffering", "no"),
+		WithHeartbeat(30*time.Second),
+	)
+	// ProfileCloudflare forbids the transformations of the stream by the
+	// Cloudflare proxy, such as the compression buffering the events, and
+	// sends the heartbeats within its 100 seconds idle timeout.
+	ProfileCloudflare = profile(
+		WithStreamHeader("Cache-Control", "no-cache, no-transform"),
+		WithHeartbeat(30*time.Second),
+	)
+	// ProfileLegacyAndroid serves the old Android browsers and the XHR based
+	// EventSource polyfills: the 2 KiB padding makes them dispatch the first
+	// events, the frequent heartbeats keep the mobile connections alive and
+	// the retry delay keeps them from reconnecting too often.
+	ProfileLegacyAndroid = profile(
+		WithPadding(2048),
+		WithHeartbeat(15*time.Second),
+		WithRetryLimits(5*time.Second, 0),
+		WithRetryPolicy(func(int) time.Duration { return 5 * time.Second }),
+	)
+)
+
+// profile returns the option applying all options of the profile.
+func profile(opts ...Option) Option {
+	return func(s *Server) {
+		for _, opt := range opts {
+			opt(s)
+		}
+	}
+}
+
+// WithStreamHeader sets the header of the stream responses of all mounts of
+// the server, replacing the default one, such as Cache-Control. The headers of
+// the handler mount are added with WithHeader.
+func WithStreamHeader(key, value string) Option {
+	return func(s *Server) {
+		if s.header == nil {
+			s.header = make(http.Header)
+		}
+		s.header.Set(key, value)
+	}
+}
+
+// WithPadding makes every connection start with the comment of the given
+// size, for the clients and the proxies buffering the beginning of the
+// response before dispatching the events.
+func WithPadding(size int) Option {
+	return func(s *Server) {
+		s.padding = size
+	}
+}
+
+// streamHeaders sets the stream headers of the server.
+func (s *Server) streamHeaders(w http.ResponseWriter) {
+	for k, v := range s.header {
+		w.Header()[k] = append([]string(nil), v...)
+	}
+}
+
+// pad adds the padding comment to the initial messages of the client.
+func (s *Server) pad(c *client) {
+	if s.padding > 1 {
+		c.initial = append(c.initial, ":"+strings.Repeat(" ", s.padding-2)+"\n")
+	}
+}
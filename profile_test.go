@@ -0,0 +1,56 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProfiles(t *testing.T) {
+	for name, test := range map[string]struct {
+		profile Option
+		header  string
+		value   string
+	}{
+		"nginx":      {ProfileNginx, "X-Accel-Buffering", "no"},
+		"cloudflare": {ProfileCloudflare, "Cache-Control", "no-cache, no-transform"},
+	} {
+		s := New(test.profile)
+		if s.heartbeat != 30*time.Second {
+			t.Errorf("%s heartbeat %v", name, s.heartbeat)
+		}
+		ts := httptest.NewServer(s)
+		req, _ := http.NewRequest("GET", ts.URL, nil)
+		req.Header.Set("Accept", "text/event-stream")
+		res, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := res.Header.Get(test.header); v != test.value {
+			t.Errorf("%s %s header %q", name, test.header, v)
+		}
+		res.Body.Close()
+		s.Close()
+		ts.Close()
+	}
+}
+
+func TestProfileLegacyAndroid(t *testing.T) {
+	s := New(ProfileLegacyAndroid)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil)
+	if msg := readMessage(t, r); len(msg) != 2048 || !strings.HasPrefix(msg, ": ") {
+		t.Errorf("padding of %d bytes", len(msg))
+	}
+	if msg := readMessage(t, r); msg != "retry: 5000\n" {
+		t.Errorf("retry %q", msg)
+	}
+	if s.heartbeat != 15*time.Second {
+		t.Errorf("heartbeat %v", s.heartbeat)
+	}
+}
@@ -0,0 +1,66 @@
+package sse
+
+import (
+	"context"
+	"time"
+)
+
+// EventOption sets the field of the event published with Publish.
+type EventOption func(*Event)
+
+// WithName sets the event name.
+func WithName(name string) EventOption {
+	return func(e *Event) {
+		e.Name = name
+	}
+}
+
+// WithID sets the event identifier.
+func WithID(id string) EventOption {
+	return func(e *Event) {
+		e.ID = id
+	}
+}
+
+// WithChannel sets the channel of the event (see WithChannels).
+func WithChannel(channel string) EventOption {
+	return func(e *Event) {
+		e.Channel = channel
+	}
+}
+
+// WithTTL sets the time the event stays relevant in the client queue.
+func WithTTL(ttl time.Duration) EventOption {
+	return func(e *Event) {
+		e.TTL = ttl
+	}
+}
+
+// WithPriority sets the delivery priority of the event.
+func WithPriority(p Priority) EventOption {
+	return func(e *Event) {
+		e.Priority = p
+	}
+}
+
+// NewEventData returns the event with the given data and options.
+func NewEventData(data string, opts ...EventOption) Event {
+	e := Event{Data: data}
+	for _, opt := range opts {
+		opt(&e)
+	}
+	return e
+}
+
+// Publish sends the event with the given data and options to all connected
+// clients:
+//
+//	s.Publish(data, sse.WithName("order"), sse.WithID(id), sse.WithTTL(time.Minute))
+//
+// Unlike the positional Event, the options cover all the event features and
+// grow with them. It returns ErrTooLarge if the encoded event exceeds the
+// maximum size.
+func (s *Server) Publish(data string, opts ...EventOption) error {
+	_, err := s.sendTo(context.Background(), NewEventData(data, opts...), nil)
+	return err
+}
@@ -0,0 +1,42 @@
+package sse
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewEventData(t *testing.T) {
+	e := NewEventData("data",
+		WithName("order"),
+		WithID("7"),
+		WithChannel("shop"),
+		WithTTL(time.Minute),
+		WithPriority(PriorityHigh),
+	)
+	want := Event{ID: "7", Name: "order", Data: "data", Channel: "shop", TTL: time.Minute, Priority: PriorityHigh}
+	if e != want {
+		t.Errorf("event %+v, want %+v", e, want)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	s := New(WithMaxEventSize(64))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+
+	errc := make(chan error, 1)
+	go func() { errc <- s.Publish("paid", WithName("order"), WithID("1")) }()
+	if msg := readMessage(t, r); msg != "event: order\ndata: paid\nid: 1\n" {
+		t.Errorf("message %q", msg)
+	}
+	if err := <-errc; err != nil {
+		t.Error(err)
+	}
+	if err := s.Publish(string(make([]byte, 100))); err != ErrTooLarge {
+		t.Errorf("error %v", err)
+	}
+}
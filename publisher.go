@@ -0,0 +1,32 @@
+package sse
+
+import (
+	"net/http"
+	"time"
+)
+
+// Publisher publishes the events to the connected clients. The application
+// code depending on the Publisher instead of the Server can be tested without
+// HTTP connections with the ssetest.Mock.
+type Publisher interface {
+	Send(e Event)
+	Publish(data string, opts ...EventOption) error
+	Event(id, name string, v interface{}) error
+	Comment(text string)
+	Retry(d time.Duration)
+	Close()
+}
+
+var _ Publisher = (*Server)(nil)
+
+// Broker is the events server: it publishes the events to the clients
+// connected over HTTP and reports its state. Frameworks and application code
+// accepting the Broker work with any backend, such as the clustered or mocked
+// one, not only the Server.
+type Broker interface {
+	Publisher
+	http.Handler
+	Stats() Stats
+}
+
+var _ Broker = (*Server)(nil)
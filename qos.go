@@ -0,0 +1,151 @@
+package sse
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+)
+
+// QoS is the delivery guarantee level of the event.
+type QoS int
+
+const (
+	// QoSFireAndForget events are sent to the connected clients only.
+	QoSFireAndForget QoS = iota
+	// QoSStore events are persisted to the EventStore (see WithEventStore)
+	// and replayed to the reconnecting clients.
+	QoSStore
+	// QoSAck events are persisted like the QoSStore ones and tracked for each
+	// client until acknowledged with AckHandler. The events not acknowledged
+	// are sent again when the client with the same identity connects.
+	QoSAck
+)
+
+const (
+	// maxPending is the maximum number of the events waiting for the
+	// acknowledgement of the client; the oldest ones are forgotten.
+	maxPending = 1000
+	// maxAckClients is the maximum number of the clients with the events
+	// waiting for the acknowledgement; the least recently updated ones are
+	// forgotten.
+	maxAckClients = 10000
+)
+
+// acks tracks the events waiting for the acknowledgement by the client
+// identity.
+type acks struct {
+	mu      sync.Mutex
+	pending map[string]*list.Element // of *pendingAcks
+	order   *list.List               // least recently updated first
+}
+
+// pendingAcks is the events waiting for the acknowledgement of the client.
+type pendingAcks struct {
+	client string
+	events []Event
+}
+
+// persist stores the event according to its QoS level and returns it with the
+// identifier assigned by the store.
+func (s *Server) persist(e Event) Event {
+	if e.QoS == QoSFireAndForget || s.store == nil {
+		return e
+	}
+	stored, err := s.store.Append(e)
+	if err != nil {
+		s.onWriteError(err, ClientInfo{})
+		return e
+	}
+	return stored
+}
+
+// track adds the event delivered to the client to the ones waiting for the
+// acknowledgement. The events are not tracked for the anonymous clients
+// without the stable identity (see WithClientID).
+func (s *Server) track(e Event, info ClientInfo) {
+	if e.QoS != QoSAck || e.ID == "" || s.clientID == nil {
+		return
+	}
+	s.acks.mu.Lock()
+	defer s.acks.mu.Unlock()
+	if s.acks.pending == nil {
+		s.acks.pending = make(map[string]*list.Element)
+		s.acks.order = list.New()
+	}
+	el, ok := s.acks.pending[info.ID]
+	if !ok {
+		el = s.acks.order.PushBack(&pendingAcks{client: info.ID})
+		s.acks.pending[info.ID] = el
+		if s.acks.order.Len() > maxAckClients {
+			s.acks.forget(s.acks.order.Front())
+		}
+	}
+	s.acks.order.MoveToBack(el)
+	pending := el.Value.(*pendingAcks)
+	for i := len(pending.events) - 1; i >= 0; i-- {
+		if pending.events[i].ID == e.ID {
+			return // delivered to other connection of the client
+		}
+	}
+	pending.events = append(pending.events, e)
+	if len(pending.events) > maxPending {
+		pending.events = pending.events[len(pending.events)-maxPending:]
+	}
+}
+
+// forget removes the pending events of the client.
+func (a *acks) forget(el *list.Element) {
+	delete(a.pending, el.Value.(*pendingAcks).client)
+	a.order.Remove(el)
+}
+
+// unacknowledged returns the events waiting for the acknowledgement of the
+// client.
+func (s *Server) unacknowledged(clientID string) []Event {
+	s.acks.mu.Lock()
+	defer s.acks.mu.Unlock()
+	el, ok := s.acks.pending[clientID]
+	if !ok {
+		return nil
+	}
+	return append([]Event(nil), el.Value.(*pendingAcks).events...)
+}
+
+// AckHandler returns the handler of the acknowledgements of the QoSAck events.
+// The client identified like the stream (see WithClientID) posts the
+// identifier of the processed event in the "id" form value; it acknowledges
+// the event and all the events before it:
+//
+//	fetch("/events/ack", {method: "POST", body: new URLSearchParams({id: e.lastEventId})})
+func (s *Server) AckHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		id := r.FormValue("id")
+		if id == "" || s.clientID == nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		client := s.clientID(r)
+
+		s.acks.mu.Lock()
+		if el, ok := s.acks.pending[client]; ok {
+			pending := el.Value.(*pendingAcks)
+			for i, e := range pending.events {
+				if e.ID == id {
+					if i+1 == len(pending.events) {
+						s.acks.forget(el)
+					} else {
+						pending.events = pending.events[i+1:]
+					}
+					break
+				}
+			}
+		}
+		s.acks.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,120 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQoSAck(t *testing.T) {
+	s := New(
+		WithEventStore(NewMemoryStore(10)),
+		WithClientID(func(r *http.Request) string { return r.Header.Get("X-User") }),
+	)
+	mux := http.NewServeMux()
+	mux.Handle("/", s)
+	mux.Handle("/ack", s.AckHandler())
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	defer s.Close()
+
+	alice := http.Header{"X-User": {"alice"}}
+	r := connect(t, ts, alice)
+	waitConnected(t, s, 1)
+	go func() {
+		s.Send(Event{Data: "paid", QoS: QoSAck})
+		s.Send(Event{Data: "shipped", QoS: QoSAck})
+	}()
+	readMessage(t, r)
+	readMessage(t, r)
+
+	ack := func(id string) {
+		t.Helper()
+		req, _ := http.NewRequest("POST", ts.URL+"/ack", strings.NewReader(url.Values{"id": {id}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-User", "alice")
+		res, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusNoContent {
+			t.Error("ack status", res.Status)
+		}
+	}
+	ack("1")
+
+	// the new connection without Last-Event-ID gets the unacknowledged event
+	r = connect(t, ts, alice)
+	if msg := readMessage(t, r); msg != "data: shipped\nid: 2\n" {
+		t.Errorf("unacknowledged %q", msg)
+	}
+	ack("2")
+	if pending := s.unacknowledged("alice"); len(pending) != 0 {
+		t.Errorf("pending %+v", pending)
+	}
+}
+
+func TestQoSAckAnonymous(t *testing.T) {
+	var s Server
+	s.track(Event{ID: "1", QoS: QoSAck}, ClientInfo{ID: "random"})
+	if len(s.acks.pending) != 0 {
+		t.Errorf("tracked anonymous %+v", s.acks.pending)
+	}
+}
+
+func TestQoSAckClientsLimit(t *testing.T) {
+	s := New(WithClientID(func(r *http.Request) string { return "" }))
+	for i := 0; i <= maxAckClients; i++ {
+		s.track(Event{ID: "1", QoS: QoSAck}, ClientInfo{ID: strconv.Itoa(i)})
+	}
+	if n := len(s.acks.pending); n != maxAckClients {
+		t.Errorf("clients %d", n)
+	}
+	if pending := s.unacknowledged("0"); len(pending) != 0 {
+		t.Errorf("the oldest client is not forgotten: %+v", pending)
+	}
+	if pending := s.unacknowledged("1"); len(pending) != 1 {
+		t.Errorf("pending %+v", pending)
+	}
+}
+
+// racingStore sends the event while the client replays the stored ones.
+type racingStore struct {
+	*MemoryStore
+	s    *Server
+	once sync.Once
+}
+
+func (r *racingStore) Since(lastEventID string) ([]Event, error) {
+	events, err := r.MemoryStore.Since(lastEventID)
+	r.once.Do(func() {
+		go r.s.Send(Event{Data: "racing", QoS: QoSStore})
+		time.Sleep(50 * time.Millisecond)
+	})
+	return events, err
+}
+
+func TestReplayJoin(t *testing.T) {
+	store := &racingStore{MemoryStore: NewMemoryStore(10)}
+	s := New(WithEventStore(store))
+	store.s = s
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	s.Send(Event{Data: "first", QoS: QoSStore})
+	s.Send(Event{Data: "missed", QoS: QoSStore})
+	r := connect(t, ts, http.Header{"Last-Event-ID": {"1"}})
+	if msg := readMessage(t, r); msg != "data: missed\nid: 2\n" {
+		t.Errorf("replayed %q", msg)
+	}
+	if msg := readMessage(t, r); msg != "data: racing\nid: 3\n" {
+		t.Errorf("sent while replaying %q", msg)
+	}
+}
@@ -0,0 +1,90 @@
+package sse
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Redact returns the middleware removing the fields of the JSON object data
+// the client has no scope for, so one broadcast can safely serve users with
+// different permission levels. The fields are given as dot-separated paths
+// mapped to the required scope; the path is applied to all elements of the
+// arrays on its way:
+//
+//	s.Use(sse.Redact(map[string]string{
+//		"salary":         "hr",
+//		"user.email":     "admin",
+//		"items.discount": "sales",
+//	}))
+//
+// The top-level arrays are walked the same way. Only the data of the JSON
+// objects and arrays is redacted: the other data, such as the plain text, is
+// delivered unchanged. The event whose data looks like the JSON object or
+// array but is not valid JSON is not delivered to the client lacking any of
+// the scopes, so the protected fields never leak.
+func Redact(fields map[string]string) Middleware {
+	return func(e Event, info ClientInfo) (Event, bool) {
+		var paths [][]string
+		for path, scope := range fields {
+			if !info.HasScope(scope) {
+				paths = append(paths, strings.Split(path, "."))
+			}
+		}
+		if len(paths) == 0 || !structured(e.Data) {
+			return e, true
+		}
+
+		dec := json.NewDecoder(strings.NewReader(e.Data))
+		dec.UseNumber()
+		var doc interface{}
+		if err := dec.Decode(&doc); err != nil || dec.More() {
+			return e, false // fail closed
+		}
+		var removed bool
+		for _, path := range paths {
+			removed = remove(doc, path) || removed
+		}
+		if !removed {
+			return e, true
+		}
+
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(doc); err != nil {
+			return e, false
+		}
+		e.Data = strings.TrimSuffix(buf.String(), "\n")
+		return e, true
+	}
+}
+
+// structured reports whether the data looks like the JSON object or array.
+func structured(data string) bool {
+	data = strings.TrimLeft(data, " \t\r\n")
+	return strings.HasPrefix(data, "{") || strings.HasPrefix(data, "[")
+}
+
+// remove removes the field at the path from the decoded JSON value and reports
+// whether anything is removed.
+func remove(v interface{}, path []string) bool {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			_, ok := v[path[0]]
+			delete(v, path[0])
+			return ok
+		}
+		if child, ok := v[path[0]]; ok {
+			return remove(child, path[1:])
+		}
+	case []interface{}:
+		var removed bool
+		for _, item := range v {
+			removed = remove(item, path) || removed
+		}
+		return removed
+	}
+	return false
+}
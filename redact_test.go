@@ -0,0 +1,50 @@
+package sse
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	mw := Redact(map[string]string{
+		"salary":         "hr",
+		"user.email":     "admin",
+		"items.discount": "sales",
+	})
+	const data = `{"items":[{"discount":5,"name":"a"},{"name":"b"}],"salary":100,"user":{"email":"a@b.c","name":"alice"}}`
+
+	for _, test := range []struct {
+		scopes []string
+		data   string
+	}{
+		{[]string{"hr", "admin", "sales"}, data},
+		{[]string{"hr"}, `{"items":[{"name":"a"},{"name":"b"}],"salary":100,"user":{"name":"alice"}}`},
+		{nil, `{"items":[{"name":"a"},{"name":"b"}],"user":{"name":"alice"}}`},
+	} {
+		e, ok := mw(Event{Name: "profile", Data: data}, ClientInfo{Scopes: test.scopes})
+		if !ok {
+			t.Fatal("event is dropped")
+		}
+		if e.Data != test.data || e.Name != "profile" {
+			t.Errorf("scopes %v:\n got %s\nwant %s", test.scopes, e.Data, test.data)
+		}
+	}
+
+	e, ok := mw(Event{Data: `[{"salary":100,"name":"a"}]`}, ClientInfo{})
+	if !ok || e.Data != `[{"name":"a"}]` {
+		t.Errorf("top-level array: %q %v", e.Data, ok)
+	}
+
+	for _, data := range []string{"plain <text>", `"salary"`, "100", ""} {
+		if e, ok := mw(Event{Data: data}, ClientInfo{}); !ok || e.Data != data {
+			t.Errorf("not JSON object: %q %v", e.Data, ok)
+		}
+	}
+
+	for _, data := range []string{`{"salary":100,}`, ` [{"salary":100}`, `{"salary":1} {"salary":2}`} {
+		if e, ok := mw(Event{Data: data}, ClientInfo{}); ok {
+			t.Errorf("invalid JSON delivered: %q", e.Data)
+		}
+		all := ClientInfo{Scopes: []string{"hr", "admin", "sales"}}
+		if e, ok := mw(Event{Data: data}, all); !ok || e.Data != data {
+			t.Errorf("invalid JSON to the privileged client: %q %v", e.Data, ok)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package sse
+
+import (
+	"errors"
+	"net/http"
+)
+
+// WithOnConnectEvents sets the function returning the events written to the
+// new connection before it joins the broadcast set: greetings, the current
+// state or the replay of the events missed since the last event identifier
+// received by the client (empty for the first connection). The events not
+// allowed for the client by the scope, channel, filter or middleware are
+// skipped.
+func WithOnConnectEvents(fn func(r *http.Request, lastEventID string) []Event) Option {
+	return func(s *Server) {
+		s.onConnect = fn
+	}
+}
+
+// WithOnLastEventID sets the function returning the events missed since the
+// last event identifier sent by the reconnecting client, for the applications
+// keeping their own history. It is called only for the requests with the
+// Last-Event-ID header, after the WithOnConnectEvents hook. The events not
+// allowed for the client are skipped.
+func WithOnLastEventID(fn func(id string) []Event) Option {
+	return func(s *Server) {
+		s.onLastID = fn
+	}
+}
+
+// errNotJoined is the registration result of the client not registered by
+// the join hook of the handler.
+var errNotJoined = errors.New("sse: client is not registered")
+
+// connectEvents adds the events of the connect hooks to the initial messages
+// of the client.
+func (s *Server) connectEvents(r *http.Request, c *client) {
+	lastID := r.Header.Get("Last-Event-ID")
+	if s.onConnect != nil {
+		s.initialEvents(c, s.onConnect(r, lastID))
+	}
+	if s.onLastID != nil && lastID != "" {
+		s.initialEvents(c, s.onLastID(lastID))
+	}
+}
+
+// joinReplay adds the retained events, the stored events missed by the client
+// and the ones waiting for its acknowledgement to the initial messages and
+// registers the client.
+// It is atomic with the fan-out of the stored events, so none of them is
+// missed in between. The client is not registered when the connection setup
+// exceeded the handshake deadline, the client has gone or has too many
+// connections.
+func (s *Server) joinReplay(r *http.Request, c *client) error {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+	s.retainedEvents(c)
+	var replayed map[string]bool // the stored events sent again
+	if events, ok, err := s.replay(r, c); ok {
+		if err != nil {
+			s.onWriteError(err, c.info)
+		}
+		replayed = make(map[string]bool, len(events))
+		for _, e := range events {
+			replayed[e.ID] = true
+		}
+		s.initialEvents(c, events)
+		if len(events) > 0 && s.tracksCursor() {
+			c.cursor = events[len(events)-1].ID
+		}
+	}
+	for _, e := range s.unacknowledged(c.info.ID) {
+		if !replayed[e.ID] {
+			s.initialEvents(c, []Event{e})
+		}
+	}
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	return s.register(c)
+}
+
+// replay returns the stored events missed by the client, if it is resuming
+// with the Last-Event-ID header, the since query parameter or the cursor of
+// the server (see WithCursors), in that order.
+func (s *Server) replay(r *http.Request, c *client) ([]Event, bool, error) {
+	if s.store == nil {
+		return nil, false, nil
+	}
+	if lastID := r.Header.Get("Last-Event-ID"); lastID != "" {
+		events, err := s.store.Since(lastID)
+		return events, true, err
+	}
+	if ts, ok := s.store.(TimeStore); ok {
+		if t, ok := since(r.URL.Query().Get("since"), clockOrSystem(s.clock).Now()); ok {
+			events, err := ts.After(t)
+			return events, true, err
+		}
+	}
+	if lastID := s.savedCursor(c); lastID != "" {
+		events, err := s.store.Since(lastID)
+		return events, true, err
+	}
+	return nil, false, nil
+}
+
+// initialEvents adds the events allowed for the client to its initial
+// messages.
+func (s *Server) initialEvents(c *client, events []Event) {
+	for _, e := range events {
+		if data, ok := s.render(c, e, s.frame(e)); ok {
+			c.initial = append(c.initial, data)
+		}
+	}
+}
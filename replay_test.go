@@ -0,0 +1,64 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOnConnectEvents(t *testing.T) {
+	s := New(
+		WithScopes(func(r *http.Request) []string { return r.Header.Values("X-Scope") }),
+		WithOnConnectEvents(func(r *http.Request, lastEventID string) []Event {
+			if lastEventID == "" {
+				return []Event{{Name: "hello", Data: "welcome"}}
+			}
+			return []Event{
+				{ID: "2", Data: "missed"},
+				{ID: "3", Data: "secret", Scope: "admin"},
+			}
+		}),
+	)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil)
+	if msg := readMessage(t, r); msg != "event: hello\ndata: welcome\n" {
+		t.Errorf("greeting %q", msg)
+	}
+
+	r = connect(t, ts, http.Header{"Last-Event-ID": {"1"}})
+	waitConnected(t, s, 2)
+	go s.Send(Event{ID: "4", Data: "live"})
+	if msg := readMessage(t, r); msg != "data: missed\nid: 2\n" {
+		t.Errorf("replay %q", msg)
+	}
+	if msg := readMessage(t, r); msg != "data: live\nid: 4\n" {
+		t.Errorf("live %q", msg)
+	}
+}
+
+func TestOnLastEventID(t *testing.T) {
+	var calls int
+	s := New(WithOnLastEventID(func(id string) []Event {
+		calls++
+		if id != "1" {
+			t.Errorf("last event id %q", id)
+		}
+		return []Event{{ID: "2", Data: "missed"}}
+	}))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	connect(t, ts, nil)
+	r := connect(t, ts, http.Header{"Last-Event-ID": {"1"}})
+	if msg := readMessage(t, r); msg != "data: missed\nid: 2\n" {
+		t.Errorf("replay %q", msg)
+	}
+	waitConnected(t, s, 2)
+	if calls != 1 {
+		t.Errorf("%d calls", calls)
+	}
+}
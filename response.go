@@ -0,0 +1,30 @@
+package sse
+
+import "net/http"
+
+// WithOnResponse sets the hook called after the response headers of the
+// events stream are prepared and before they are sent to the client. The hook
+// may add the headers and returns the status code of the response, such as
+// 201 Created with the Location of the subscription for the APIs treating
+// the stream as the resource. The zero or not successful (2xx) status codes,
+// as well as 204 No Content and 205 Reset Content not allowing the response
+// body, are replaced by 200 OK: reject the requests with WithAuth instead. Note
+// that the browsers' EventSource accepts only 200 OK.
+func WithOnResponse(fn func(r *http.Request, info ClientInfo, header http.Header) int) Option {
+	return func(s *Server) {
+		s.onResponse = fn
+	}
+}
+
+// writeHeader sends the response headers with the status code returned by the
+// response hook, if any.
+func (s *Server) writeHeader(w http.ResponseWriter, r *http.Request, info ClientInfo) {
+	if s.onResponse == nil {
+		return
+	}
+	code := s.onResponse(r, info, w.Header())
+	if code < 200 || code > 299 || code == http.StatusNoContent || code == http.StatusResetContent {
+		code = http.StatusOK
+	}
+	w.WriteHeader(code)
+}
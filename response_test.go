@@ -0,0 +1,49 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOnResponse(t *testing.T) {
+	s := New(WithOnResponse(func(r *http.Request, info ClientInfo, header http.Header) int {
+		switch r.URL.Query().Get("status") {
+		case "bad":
+			return http.StatusForbidden
+		case "empty":
+			return http.StatusNoContent
+		case "reset":
+			return http.StatusResetContent
+		}
+		header.Set("Location", "/subscriptions/"+info.ID)
+		return http.StatusCreated
+	}))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	get := func(query string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest("GET", ts.URL+query, nil)
+		req.Header.Set("Accept", "text/event-stream")
+		res, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		return res
+	}
+	res := get("")
+	if res.StatusCode != http.StatusCreated || res.Header.Get("Location") == "" {
+		t.Errorf("response %s, location %q", res.Status, res.Header.Get("Location"))
+	}
+	if res.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("content type %q", res.Header.Get("Content-Type"))
+	}
+	for _, status := range []string{"bad", "empty", "reset"} {
+		if res := get("?status=" + status); res.StatusCode != http.StatusOK {
+			t.Errorf("%s status %s", status, res.Status)
+		}
+	}
+}
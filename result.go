@@ -0,0 +1,77 @@
+package sse
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrClientGone is the delivery error of the client disconnected while the
+	// event is sent.
+	ErrClientGone = errors.New("sse: client disconnected")
+	// ErrSlowClient is the delivery error of the client disconnected by the
+	// SlowClientDisconnect policy because its queue is full.
+	ErrSlowClient = errors.New("sse: slow client disconnected")
+	// ErrNoClients is returned by EventTo when no connection of the client
+	// with the identity is registered.
+	ErrNoClients = errors.New("sse: client is not connected")
+	// ErrClosed is returned by the publishers used after Close, such as Peers.
+	ErrClosed = errors.New("sse: publisher is closed")
+	// ErrUnauthorized matches the ErrBadStatus of the stream rejected with the
+	// 401 Unauthorized or 403 Forbidden status, as with WithAuth:
+	//
+	//	if errors.Is(err, sse.ErrUnauthorized) {
+	//		renewToken()
+	//	}
+	ErrUnauthorized = errors.New("sse: unauthorized")
+
+	// errDropped is the delivery error of the event dropped by the
+	// SlowClientDrop policy, counted as dropped.
+	errDropped = errors.New("sse: event dropped")
+)
+
+// SendResult is the delivery result of the event.
+type SendResult struct {
+	Delivered int           // number of clients the event is queued for
+	Dropped   int           // number of slow clients the event is dropped for
+	Failed    []ClientError // clients failed to get the event
+}
+
+// ClientError is the delivery error of the client.
+type ClientError struct {
+	Client ClientInfo
+	Err    error
+}
+
+// Error implements the error interface.
+func (e ClientError) Error() string {
+	return e.Client.ID + ": " + e.Err.Error()
+}
+
+// Unwrap returns the delivery error.
+func (e ClientError) Unwrap() error {
+	return e.Err
+}
+
+// add adds the delivery result of the client.
+func (r *SendResult) add(info ClientInfo, sent bool, err error) {
+	switch {
+	case !sent:
+	case err == nil:
+		r.Delivered++
+	case err == errDropped:
+		r.Dropped++
+	default:
+		r.Failed = append(r.Failed, ClientError{Client: info, Err: err})
+	}
+}
+
+// SendResult sends the event like Send and returns the delivery result, so
+// the publisher needing the delivery visibility does not have to correlate
+// the errors of the WithOnError hook. The event is delivered when it is queued
+// for the client; the write errors are reported to the WithOnError hook later.
+// The event exceeding the maximum size is not delivered to anybody.
+func (s *Server) SendResult(e Event) SendResult {
+	result, _ := s.sendTo(context.Background(), e, nil)
+	return result
+}
@@ -0,0 +1,74 @@
+package sse
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendResult(t *testing.T) {
+	for policy, slow := range map[SlowClientPolicy]func(SendResult) bool{
+		SlowClientDrop: func(r SendResult) bool { return r.Dropped == 1 },
+		SlowClientDisconnect: func(r SendResult) bool {
+			return len(r.Failed) == 1 && errors.Is(r.Failed[0], ErrSlowClient) &&
+				r.Failed[0].Client.ID == "slow"
+		},
+	} {
+		s := New(
+			WithBufferSize(1),
+			WithSlowClientPolicy(policy),
+			WithClientID(func(r *http.Request) string { return r.Header.Get("X-ID") }),
+		)
+		ts := httptest.NewServer(s)
+
+		req, _ := http.NewRequest("GET", ts.URL, nil)
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("X-ID", "slow")
+		res, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		waitConnected(t, s, 1)
+
+		// the client does not read, so the queue overflows sooner or later
+		data := string(make([]byte, 1<<16))
+		var result SendResult
+		for i := 0; i < 1000; i++ {
+			if result = s.SendResult(Event{Data: data}); result.Delivered == 0 {
+				break
+			}
+		}
+		if !slow(result) {
+			t.Errorf("policy %d: result %+v", policy, result)
+		}
+		if result := s.SendResult(Event{Data: "filtered", Channel: "none"}); result.Delivered+result.Dropped+len(result.Failed) != 0 {
+			t.Errorf("filtered result %+v", result)
+		}
+
+		res.Body.Close()
+		s.Close()
+		ts.Close()
+	}
+}
+
+func TestEventToNoClients(t *testing.T) {
+	s := New(WithClientID(func(r *http.Request) string { return r.Header.Get("X-User") }))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	if err := s.EventTo("alice", "", "", "x"); err != ErrNoClients {
+		t.Errorf("error %v, want %v", err, ErrNoClients)
+	}
+	r := connect(t, ts, http.Header{"X-User": {"alice"}})
+	waitConnected(t, s, 1)
+	go func() {
+		if err := s.EventTo("alice", "", "", "x"); err != nil {
+			t.Error(err)
+		}
+	}()
+	if msg := readMessage(t, r); msg != "data: x\n" {
+		t.Errorf("event %q", msg)
+	}
+}
@@ -0,0 +1,61 @@
+package sse
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ResumeStore keeps the identifier of the last event received by the Client
+// across the restarts of the consumer process.
+type ResumeStore interface {
+	// Load returns the saved identifier or empty string.
+	Load() (string, error)
+	// Save saves the identifier of the last received event.
+	Save(lastEventID string) error
+}
+
+// WithResumeStore sets the store of the last event identifier: the client
+// loads it before the first connection, overriding WithLastEventID if saved,
+// and saves it after each event is received from the events channel, so the
+// restarted consumer resumes from where it left off. The events received but
+// not taken from the channel are delivered again after the restart.
+func WithResumeStore(store ResumeStore) ClientOption {
+	return func(c *Client) {
+		c.resume = store
+	}
+}
+
+// FileResumeStore is the ResumeStore keeping the identifier in the file with
+// the given name. The file is replaced atomically on save.
+type FileResumeStore string
+
+// Load implements ResumeStore interface. The missing file means no saved
+// identifier.
+func (f FileResumeStore) Load() (string, error) {
+	data, err := os.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Save implements ResumeStore interface.
+func (f FileResumeStore) Save(lastEventID string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(string(f)), filepath.Base(string(f))+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // after the failure
+	if _, err := tmp.WriteString(lastEventID + "\n"); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), string(f))
+}
@@ -0,0 +1,57 @@
+package sse
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileResumeStore(t *testing.T) {
+	store := FileResumeStore(filepath.Join(t.TempDir(), "last-event-id"))
+	if id, err := store.Load(); err != nil || id != "" {
+		t.Errorf("missing file: %q, %v", id, err)
+	}
+	if err := store.Save("42"); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := store.Load(); err != nil || id != "42" {
+		t.Errorf("loaded %q, %v", id, err)
+	}
+}
+
+func TestClientResumeStore(t *testing.T) {
+	s := New()
+	lastIDs := make(chan string, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastIDs <- r.Header.Get("Last-Event-ID")
+		s.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	defer s.Close()
+	store := FileResumeStore(filepath.Join(t.TempDir(), "last-event-id"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := NewClient(ts.URL, WithResumeStore(store)).Events(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-lastIDs
+	waitConnected(t, s, 1)
+	go s.Send(Event{ID: "5", Data: "five"})
+	<-events
+	cancel()
+	for range events {
+	}
+
+	// the restarted consumer
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	if _, err := NewClient(ts.URL, WithResumeStore(store), WithLastEventID("1")).Events(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if id := <-lastIDs; id != "5" {
+		t.Errorf("Last-Event-ID %q, want 5", id)
+	}
+}
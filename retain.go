@@ -0,0 +1,80 @@
+package sse
+
+// WithRetain makes the server remember the latest event sent per key and
+// send the retained events to every new connection before the live ones, as
+// the MQTT retained messages: it suits the status and configuration streams,
+// where the client needs the current value at once. The key function returns
+// the retention key of the event or an empty string to not retain it; a nil
+// function retains the latest event per name, and the events without the name
+// under the "message" name. The retained events not allowed for the client by
+// the scope, channel, filter or middleware are skipped.
+func WithRetain(key func(Event) string) Option {
+	return func(s *Server) {
+		if key == nil {
+			key = func(e Event) string {
+				if e.Name == "" {
+					return "message"
+				}
+				return e.Name
+			}
+		}
+		s.retainKey = key
+	}
+}
+
+// Retained returns the retained events in the order their keys were first
+// retained.
+func (s *Server) Retained() []Event {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+	events := make([]Event, 0, len(s.retainOrder))
+	for _, key := range s.retainOrder {
+		events = append(events, s.retained[key])
+	}
+	return events
+}
+
+// Unretain forgets the retained event with the key, so the new connections do
+// not receive it anymore.
+func (s *Server) Unretain(key string) {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+	if _, ok := s.retained[key]; !ok {
+		return
+	}
+	delete(s.retained, key)
+	for i, k := range s.retainOrder {
+		if k == key {
+			s.retainOrder = append(s.retainOrder[:i], s.retainOrder[i+1:]...)
+			break
+		}
+	}
+}
+
+// retain remembers the event sent if it has the retention key. It must be
+// called with the replayMu held, so the joining clients get either the
+// retained event or the live one.
+func (s *Server) retain(e Event) {
+	if s.retainKey == nil {
+		return
+	}
+	key := s.retainKey(e)
+	if key == "" {
+		return
+	}
+	if s.retained == nil {
+		s.retained = make(map[string]Event)
+	}
+	if _, ok := s.retained[key]; !ok {
+		s.retainOrder = append(s.retainOrder, key)
+	}
+	s.retained[key] = e
+}
+
+// retainedEvents adds the retained events to the initial messages of the
+// client. It must be called with the replayMu held.
+func (s *Server) retainedEvents(c *client) {
+	for _, key := range s.retainOrder {
+		s.initialEvents(c, []Event{s.retained[key]})
+	}
+}
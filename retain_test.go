@@ -0,0 +1,52 @@
+package sse
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRetain(t *testing.T) {
+	s := New(WithRetain(nil))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	s.Send(Event{Name: "status", Data: "starting"})
+	s.Send(Event{Name: "config", Data: "v1"})
+	s.Send(Event{Name: "status", Data: "ready"})
+	s.Send(Event{Name: "secret", Data: "x", Scope: "admin"})
+	if n := len(s.Retained()); n != 3 {
+		t.Errorf("%d events retained", n)
+	}
+
+	r := connect(t, ts, nil)
+	// the replaced event keeps its place, the one out of scope is skipped
+	if msg := readMessage(t, r); msg != "event: status\ndata: ready\n" {
+		t.Errorf("first retained %q", msg)
+	}
+	if msg := readMessage(t, r); msg != "event: config\ndata: v1\n" {
+		t.Errorf("second retained %q", msg)
+	}
+	waitConnected(t, s, 1)
+	s.Send(Event{Data: "live"})
+	if msg := readMessage(t, r); msg != "data: live\n" {
+		t.Errorf("live event %q", msg)
+	}
+
+	s.Unretain("status")
+	s.Unretain("message")
+	events := s.Retained()
+	if len(events) != 2 || events[0].Name != "config" || events[1].Name != "secret" {
+		t.Errorf("retained %+v", events)
+	}
+}
+
+func TestRetainKey(t *testing.T) {
+	s := New(WithRetain(func(e Event) string { return e.Channel }))
+	s.Send(Event{Data: "1", Channel: "a"})
+	s.Send(Event{Data: "2"})
+	s.Send(Event{Data: "3", Channel: "a"})
+	if events := s.Retained(); len(events) != 1 || events[0].Data != "3" {
+		t.Errorf("retained %+v", events)
+	}
+}
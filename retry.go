@@ -0,0 +1,70 @@
+package sse
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidRetry is reported to the WithOnError hook for the negative
+// reconnection delay sent with Retry; the delay is not sent then.
+var ErrInvalidRetry = errors.New("sse: negative reconnection delay")
+
+// WithRetryLimits sets the range of the reconnection delays sent to the
+// clients by Retry, the client classes and the retry policy: the shorter
+// delays are raised to min and the longer ones lowered to max, so a bug or
+// the overloaded policy does not make all browsers reconnect at once or give
+// up for hours. Zero does not limit the delay.
+func WithRetryLimits(min, max time.Duration) Option {
+	return func(s *Server) {
+		s.retryMin, s.retryMax = min, max
+	}
+}
+
+// WithRetryPolicy sets the function computing the reconnection delay sent to
+// the new connections (the retry field) from the current load, so the
+// overloaded server tells the browsers to back off further. The function gets
+// the number of connected clients and may check other signals, such as the
+// memory pressure. The zero delay is not sent.
+//
+//	sse.WithRetryPolicy(func(connected int) time.Duration {
+//		return time.Second + time.Duration(connected/1000)*time.Second
+//	})
+func WithRetryPolicy(fn func(connected int) time.Duration) Option {
+	return func(s *Server) {
+		s.retryPolicy = fn
+	}
+}
+
+// adviseRetry adds the reconnection delay of the client class or the retry
+// policy to the initial messages of the client.
+func (s *Server) adviseRetry(c *client) {
+	if d := s.class(c.info).Retry; d > 0 {
+		c.initial = append(c.initial, retryField(s.clampRetry(d)))
+		return
+	}
+	if s.retryPolicy == nil {
+		return
+	}
+	if d := s.retryPolicy(s.Connected()); d > 0 {
+		c.initial = append(c.initial, retryField(s.clampRetry(d)))
+	}
+}
+
+// clampRetry returns the reconnection delay within the retry limits.
+func (s *Server) clampRetry(d time.Duration) time.Duration {
+	if s.retryMin > 0 && d < s.retryMin {
+		d = s.retryMin
+	}
+	if s.retryMax > 0 && d > s.retryMax {
+		d = s.retryMax
+	}
+	return d
+}
+
+// retryField returns the retry field of the reconnection delay in the
+// milliseconds. The delay is rounded up, so the sub-millisecond one is not
+// sent as zero, which means to reconnect immediately.
+func retryField(d time.Duration) string {
+	return fmt.Sprintln("retry:", int64((d+time.Millisecond-1)/time.Millisecond))
+}
@@ -0,0 +1,68 @@
+package sse
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	s := New(WithRetryPolicy(func(connected int) time.Duration {
+		return time.Second + time.Duration(connected)*time.Second
+	}))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	if msg := readMessage(t, connect(t, ts, nil)); msg != "retry: 1000\n" {
+		t.Errorf("first retry %q", msg)
+	}
+	waitConnected(t, s, 1)
+	if msg := readMessage(t, connect(t, ts, nil)); msg != "retry: 2000\n" {
+		t.Errorf("second retry %q", msg)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var reported error
+	s := New(WithRetryLimits(time.Second, time.Minute), WithOnError(func(err error, info ClientInfo) { reported = err }))
+	c := &client{retryDue: make(chan struct{}, 1), done: make(chan struct{})}
+	s.clients = map[*client]struct{}{c: {}}
+	for d, want := range map[time.Duration]string{
+		0:                       "retry: 1000\n",
+		2 * time.Second:         "retry: 2000\n",
+		1500 * time.Microsecond: "retry: 1000\n",
+		time.Hour:               "retry: 60000\n",
+	} {
+		s.Retry(d)
+		<-c.retryDue
+		if data := *c.retry.Swap(nil); data != want {
+			t.Errorf("%v sent as %q, want %q", d, data, want)
+		}
+	}
+	s.Retry(-time.Second)
+	if reported != ErrInvalidRetry || c.retry.Load() != nil {
+		t.Errorf("negative retry reported %v, queued %v", reported, c.retry.Load())
+	}
+
+	// the retry directives coalesce to the latest one
+	s.Retry(time.Second)
+	s.Retry(2 * time.Second)
+	<-c.retryDue
+	if data := *c.retry.Swap(nil); data != "retry: 2000\n" || len(c.retryDue) != 0 {
+		t.Errorf("coalesced retry %q", data)
+	}
+}
+
+func TestRetryField(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                       "retry: 0\n",
+		time.Microsecond:        "retry: 1\n",
+		1500 * time.Microsecond: "retry: 2\n",
+		3 * time.Second:         "retry: 3000\n",
+	} {
+		if field := retryField(d); field != want {
+			t.Errorf("%v is %q, want %q", d, field, want)
+		}
+	}
+}
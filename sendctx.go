@@ -0,0 +1,37 @@
+package sse
+
+import "context"
+
+// SendContext sends the event to all connected clients allowed to receive it
+// like Send, but stops waiting for the slow clients (see SlowClientBlock) when
+// the context is done, so the publisher embedded in the request handler does
+// not outlive its own request. It returns the context error then, and the
+// clients not reached yet do not get the event, or ErrTooLarge if the encoded
+// event exceeds the maximum size. The event gets the trace context of the
+// context (see Traced).
+func (s *Server) SendContext(ctx context.Context, e Event) error {
+	_, err := s.sendTo(ctx, s.Traced(ctx, e), nil)
+	return err
+}
+
+// EventContext sends an event with the given data encoded as JSON to all
+// connected clients like Event, but stops when the context is done (see
+// SendContext).
+func (s *Server) EventContext(ctx context.Context, id, name string, v interface{}) error {
+	e, err := NewEvent(id, name, v)
+	if err != nil {
+		return err
+	}
+	return s.SendContext(ctx, e)
+}
+
+// CommentContext sends the comment to all connected clients like Comment,
+// unless the context is done. The comments never wait for the clients, so
+// the context is only checked before.
+func (s *Server) CommentContext(ctx context.Context, text string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.Comment(text)
+	return nil
+}
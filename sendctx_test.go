@@ -0,0 +1,50 @@
+package sse
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendContext(t *testing.T) {
+	s := New() // the clients are waited for
+	slow := &client{messages: make(chan message), control: make(chan string, controlLane), done: make(chan struct{})}
+	defer slow.disconnect()
+	s.mu.Lock()
+	s.clients = map[*client]struct{}{slow: {}}
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.SendContext(ctx, Event{Data: "x"}); err != context.DeadlineExceeded {
+		t.Errorf("blocked send error %v", err)
+	}
+	if err := s.EventContext(ctx, "", "", "x"); err != context.DeadlineExceeded {
+		t.Errorf("event error %v", err)
+	}
+	if err := s.CommentContext(ctx, "x"); err != context.DeadlineExceeded {
+		t.Errorf("comment error %v", err)
+	}
+	if n := s.Stats().Delivered; n != 0 {
+		t.Errorf("%d delivered", n)
+	}
+
+	// the client taking the event gets it with the trace of the context
+	go func() {
+		m := <-slow.messages
+		if !strings.Contains(m.data, "trace: "+traceparent) {
+			t.Errorf("message %q", m.data)
+		}
+	}()
+	ctx = ContextWithTraceParent(context.Background(), traceparent)
+	if err := s.EventContext(ctx, "", "", "x"); err != nil {
+		t.Error(err)
+	}
+	if err := s.CommentContext(ctx, "x"); err != nil {
+		t.Error(err)
+	}
+	if data := <-slow.control; data != ": x\n" {
+		t.Errorf("comment %q", data)
+	}
+}
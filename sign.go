@@ -0,0 +1,49 @@
+package sse
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// WithSigningKey enables signing of the events: each event gets an additional
+// "sig" field with the HMAC-SHA256 signature of all its fields written to the
+// stream, the identifier, name, trace context and data, calculated with the
+// given key. The browsers ignore the unknown fields, and
+// the consumers of relayed streams can check the signature with Verify to
+// detect tampering.
+func WithSigningKey(key []byte) Option {
+	return func(s *Server) {
+		s.signKey = key
+	}
+}
+
+// Sign returns the base64-encoded HMAC-SHA256 signature of the event
+// calculated with the given key.
+func Sign(key []byte, e Event) string {
+	return base64.RawStdEncoding.EncodeToString(signature(key, e))
+}
+
+// Verify reports whether sig is a valid signature of the event received from
+// the stream signed with the given key.
+func Verify(key []byte, e Event, sig string) bool {
+	mac, err := base64.RawStdEncoding.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(mac, signature(key, e))
+}
+
+// signature returns the HMAC-SHA256 of the event fields as the clients see
+// them in the stream: the single line fields with the line breaks escaped and
+// the data with the line breaks normalized. The channel, not written to the
+// stream, is signed for the consumers getting it, such as the Webhook.
+func signature(key []byte, e Event) []byte {
+	mac := hmac.New(sha256.New, key)
+	for _, field := range []string{e.ID, e.Name, e.Trace, e.Channel} {
+		mac.Write([]byte(newlineReplacer.Replace(field)))
+		mac.Write([]byte{'\n'})
+	}
+	mac.Write([]byte(lineBreaks.Replace(e.Data)))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,64 @@
+package sse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	key := []byte("secret")
+	s := New(WithSigningKey(key))
+	e := Event{ID: "1", Name: "price", Data: "{\"symbol\":\"ACME\",\n\"price\":42}"}
+
+	var sig string
+	for _, line := range strings.Split(s.encode(e), "\n") {
+		if strings.HasPrefix(line, "sig: ") {
+			sig = strings.TrimPrefix(line, "sig: ")
+		}
+	}
+	if sig == "" {
+		t.Fatal("signature field not found")
+	}
+	if !Verify(key, e, sig) {
+		t.Error("valid signature rejected")
+	}
+
+	tampered := e
+	tampered.Data = "{\"symbol\":\"ACME\",\n\"price\":1}"
+	if Verify(key, tampered, sig) {
+		t.Error("tampered event accepted")
+	}
+	if Verify([]byte("other"), e, sig) {
+		t.Error("signature accepted with a wrong key")
+	}
+	if Verify(key, e, "not base64!") {
+		t.Error("malformed signature accepted")
+	}
+}
+
+func TestSignFields(t *testing.T) {
+	key := []byte("secret")
+	s := New(WithSigningKey(key))
+	e := Event{ID: "1\r", Name: "log", Data: "line\r\nnext\rlast\r", Trace: traceparent, Channel: "logs"}
+	d := NewDecoder(strings.NewReader(s.encode(e) + "\n"))
+	received, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := d.Field("sig")
+	if received.Data != "line\nnext\nlast\n" || !Verify(key, received, sig) {
+		t.Errorf("received %+v is not verified", received)
+	}
+	for name, tampered := range map[string]Event{
+		"trace":   {ID: received.ID, Name: "log", Data: received.Data, Trace: "00-other"},
+		"channel": {ID: received.ID, Name: "log", Data: received.Data, Trace: traceparent, Channel: "admin"},
+	} {
+		if Verify(key, tampered, sig) {
+			t.Errorf("tampered %s accepted", name)
+		}
+	}
+	hook := Event{ID: "1", Data: "x", Channel: "logs"}
+	if !Verify(key, hook, Sign(key, hook)) || Verify(key, Event{ID: "1", Data: "x"}, Sign(key, hook)) {
+		t.Error("channel is not signed")
+	}
+}
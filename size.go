@@ -0,0 +1,39 @@
+package sse
+
+import "errors"
+
+// ErrTooLarge is returned when the encoded event exceeds the maximum size set
+// with WithMaxEventSize.
+var ErrTooLarge = errors.New("sse: event too large")
+
+// WithMaxEventSize sets the maximum size of the encoded event in bytes. The
+// larger events are not sent: instead of streaming the multi-megabyte frame
+// stalling the queues of all clients, ErrTooLarge is reported to the
+// WithOnError hook and returned by Event and EventTo. The size of the event
+// encoded for each client, after the middleware and the metadata, is checked
+// too: the client does not receive the enlarged event, and the error is
+// reported to the hook with the client and in SendResult.Failed.
+func WithMaxEventSize(n int) Option {
+	return func(s *Server) {
+		s.maxSize = n
+	}
+}
+
+// checkSize returns ErrTooLarge if the encoded event exceeds the maximum size.
+func (s *Server) checkSize(data string) error {
+	if s.maxSize <= 0 || len(data) <= s.maxSize {
+		return nil
+	}
+	return ErrTooLarge
+}
+
+// reportTooLarge reports the clients the event is too large for to the error
+// hook. It is called after the fan-out, with the server unlocked, so the hook
+// may use the server.
+func (s *Server) reportTooLarge(result SendResult) {
+	for _, failed := range result.Failed {
+		if failed.Err == ErrTooLarge {
+			s.onWriteError(ErrTooLarge, failed.Client)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package sse
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxEventSize(t *testing.T) {
+	var errs []error
+	s := New(
+		WithMaxEventSize(64),
+		WithOnError(func(err error, _ ClientInfo) { errs = append(errs, err) }),
+	)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	big := strings.Repeat("x", 100)
+	if err := s.Event("", "export", big); err != ErrTooLarge {
+		t.Errorf("event error %v", err)
+	}
+	if result := s.SendResult(Event{Data: big}); result.Delivered != 0 {
+		t.Errorf("large event delivered %+v", result)
+	}
+	go s.Send(Event{Data: "small"})
+	if msg := readMessage(t, r); msg != "data: small\n" {
+		t.Errorf("message %q", msg)
+	}
+	if len(errs) != 2 || errs[0] != ErrTooLarge || errs[1] != ErrTooLarge {
+		t.Errorf("errors %v", errs)
+	}
+}
+
+func TestMaxEventSizePerClient(t *testing.T) {
+	var errs []error
+	var s *Server
+	s = New(
+		WithMaxEventSize(64),
+		WithOnError(func(err error, _ ClientInfo) {
+			if !s.mu.TryLock() { // the hook may use the server
+				t.Error("error reported with the server locked")
+			} else {
+				s.mu.Unlock()
+			}
+			errs = append(errs, err)
+		}),
+	)
+	s.Use(func(e Event, info ClientInfo) (Event, bool) {
+		if e.Name == "enlarged" {
+			e.Data = strings.Repeat("x", 100)
+		}
+		return e, true
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	result := s.SendResult(Event{Name: "enlarged", Data: "small"})
+	if result.Delivered != 0 || len(result.Failed) != 1 || result.Failed[0].Err != ErrTooLarge {
+		t.Errorf("enlarged event %+v", result)
+	}
+	big := strings.Repeat("x", 100)
+	if err := s.SendLazy("lazy", func(ClientInfo) (string, error) { return big, nil }); err != ErrTooLarge {
+		t.Errorf("lazy event error %v", err)
+	}
+	go s.Send(Event{Data: "small"})
+	if msg := readMessage(t, r); msg != "data: small\n" {
+		t.Errorf("message %q", msg)
+	}
+	if len(errs) != 2 || errs[0] != ErrTooLarge || errs[1] != ErrTooLarge {
+		t.Errorf("errors %v", errs)
+	}
+}
@@ -0,0 +1,151 @@
+package sse
+
+import (
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync/atomic"
+	"time"
+)
+
+// Limit is the limit of the server resources watched by the soft limits.
+type Limit int
+
+const (
+	// LimitClients is the number of the connected clients.
+	LimitClients Limit = iota
+	// LimitMemory is the memory used by the process.
+	LimitMemory
+	// LimitQueue is the number of the events queued for the client, limited
+	// by the buffer size (see WithBufferSize).
+	LimitQueue
+)
+
+// String returns the name of the limit.
+func (l Limit) String() string {
+	switch l {
+	case LimitClients:
+		return "clients"
+	case LimitMemory:
+		return "memory"
+	case LimitQueue:
+		return "queue"
+	default:
+		return "unknown"
+	}
+}
+
+// LimitWarning is the warning of the approached limit.
+type LimitWarning struct {
+	Limit  Limit
+	Value  int64      // current value
+	Max    int64      // hard limit
+	Client ClientInfo // client of the queue warning
+}
+
+// SoftLimits are the hard limits of the server and the threshold of the
+// warnings about them.
+type SoftLimits struct {
+	// Threshold is the ratio of the limits issuing the warnings, 0.8 if zero.
+	Threshold float64
+	// Clients is the number of the clients the server can serve, such as by
+	// the open files limit of the process; not watched if zero.
+	Clients int
+	// Memory is the memory limit of the process in bytes; the runtime memory
+	// limit (see debug.SetMemoryLimit), if set, when zero.
+	Memory int64
+}
+
+// WithSoftLimits makes the server warn about the clients count, the memory
+// used and the queue depth of every client approaching the limits, so the
+// operators alert and scale before the clients are rejected or their events
+// dropped:
+//
+//	sse.WithSoftLimits(sse.SoftLimits{Clients: 10000}, func(w sse.LimitWarning) {
+//		log.Printf("%s at %d of %d", w.Limit, w.Value, w.Max)
+//	})
+//
+// The warning is issued once when the value crosses the threshold and again
+// only after it falls below. The function is called in its own goroutine. The
+// memory is checked at most once a second, when the events are sent or the
+// clients connect.
+func WithSoftLimits(limits SoftLimits, warn func(LimitWarning)) Option {
+	return func(s *Server) {
+		if limits.Threshold <= 0 {
+			limits.Threshold = 0.8
+		}
+		if limits.Memory <= 0 {
+			if limit := debug.SetMemoryLimit(-1); limit != math.MaxInt64 {
+				limits.Memory = limit
+			}
+		}
+		s.limits = &softLimits{SoftLimits: limits, warn: warn}
+	}
+}
+
+// softLimits is the state of the soft limits warnings.
+type softLimits struct {
+	SoftLimits
+	warn    func(LimitWarning)
+	clients atomic.Bool  // clients warning is issued
+	memory  atomic.Bool  // memory warning is issued
+	checked atomic.Int64 // time of the last memory check in nanoseconds
+}
+
+// check issues the warning when the value crosses the threshold of the limit
+// and rearms it when the value falls below.
+func (l *softLimits) check(warned *atomic.Bool, w LimitWarning) {
+	if w.Max <= 0 {
+		return
+	}
+	if float64(w.Value) < l.Threshold*float64(w.Max) {
+		warned.Store(false)
+		return
+	}
+	if warned.CompareAndSwap(false, true) {
+		go l.warn(w)
+	}
+}
+
+// watchClients checks the number of the clients.
+func (s *Server) watchClients(n int) {
+	if s.limits != nil {
+		s.limits.check(&s.limits.clients, LimitWarning{Limit: LimitClients, Value: int64(n), Max: int64(s.limits.Clients)})
+	}
+}
+
+// watchQueue checks the queue depth of the client.
+func (s *Server) watchQueue(c *client) {
+	if s.limits != nil {
+		s.limits.check(&c.queueWarned, LimitWarning{
+			Limit:  LimitQueue,
+			Value:  int64(len(c.messages)),
+			Max:    int64(cap(c.messages)),
+			Client: c.info,
+		})
+	}
+}
+
+// memoryMetric is the runtime metric of the memory limited by the runtime.
+var memoryMetric = []metrics.Sample{
+	{Name: "/memory/classes/total:bytes"},
+	{Name: "/memory/classes/heap/released:bytes"},
+}
+
+// watchMemory checks the memory used by the process, at most once a second.
+func (s *Server) watchMemory() {
+	l := s.limits
+	if l == nil || l.Memory <= 0 {
+		return
+	}
+	now := clockOrSystem(s.clock).Now().UnixNano()
+	last := l.checked.Load()
+	if now-last < int64(time.Second) || !l.checked.CompareAndSwap(last, now) {
+		return
+	}
+	samples := make([]metrics.Sample, len(memoryMetric))
+	copy(samples, memoryMetric)
+	metrics.Read(samples)
+	used := samples[0].Value.Uint64() - samples[1].Value.Uint64()
+	l.check(&l.memory, LimitWarning{Limit: LimitMemory, Value: int64(used), Max: l.Memory})
+}
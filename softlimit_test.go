@@ -0,0 +1,62 @@
+package sse
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSoftLimits(t *testing.T) {
+	warnings := make(chan LimitWarning, 10)
+	s := New(WithSoftLimits(SoftLimits{Threshold: 0.5, Clients: 4, Memory: 1}, func(w LimitWarning) { warnings <- w }),
+		WithBufferSize(2), WithSlowClientPolicy(SlowClientDrop))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	next := func() LimitWarning {
+		t.Helper()
+		select {
+		case w := <-warnings:
+			return w
+		case <-time.After(time.Second):
+			t.Fatal("no warning")
+			return LimitWarning{}
+		}
+	}
+	connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	if w := next(); w.Limit != LimitMemory || w.Value <= 0 || w.Max != 1 {
+		t.Errorf("memory warning %+v", w)
+	}
+	connect(t, ts, nil)
+	waitConnected(t, s, 2)
+	if w := next(); w.Limit != LimitClients || w.Value != 2 || w.Max != 4 {
+		t.Errorf("clients warning %+v", w)
+	}
+	connect(t, ts, nil)
+	waitConnected(t, s, 3)
+	select {
+	case w := <-warnings:
+		t.Errorf("repeated warning %+v", w)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// the queue of the stalled client
+	c := &client{info: ClientInfo{ID: "slow"}, messages: make(chan message, 2), done: make(chan struct{})}
+	s.mu.Lock()
+	clients := s.clients
+	s.clients = map[*client]struct{}{c: {}}
+	s.mu.Unlock()
+	s.Send(Event{Data: "1"})
+	if w := next(); w.Limit != LimitQueue || w.Value != 1 || w.Max != 2 || w.Client.ID != "slow" {
+		t.Errorf("queue warning %+v", w)
+	}
+	s.mu.Lock()
+	s.clients = clients
+	s.mu.Unlock()
+
+	if LimitQueue.String() != "queue" || Limit(-1).String() != "unknown" {
+		t.Error("limit names")
+	}
+}
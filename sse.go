@@ -4,19 +4,296 @@
 package sse
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"mime"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Server provides HTML5 Server-Sent Events
 type Server struct {
-	clients map[chan string]struct{} // connected clients
-	mu      sync.RWMutex
+	sent      atomic.Uint64 // number of events sent
+	delivered atomic.Uint64 // number of messages queued to the clients
+	dropped   atomic.Uint64 // number of messages dropped for the slow clients
+	expired   atomic.Uint64 // number of expired events
+
+	envelopeSeq atomic.Uint64 // number of the events wrapped in the envelope
+
+	clients  map[*client]struct{}           // connected clients
+	clientID func(r *http.Request) string   // client identity extractor
+	scopes   func(r *http.Request) []string // client scopes extractor
+	channels func(r *http.Request) []string // client channels extractor
+	signKey  []byte                         // event signature key
+	buffer   int                            // client queue size
+	policy   SlowClientPolicy               // client queue overflow policy
+	rate     float64                        // events per second per client
+	burst    int                            // rate limit burst size
+	accepts  *acceptLimiter                 // connections rate limiter
+	breaker  *breakerConfig                 // write failures circuit breaker
+	onError  func(error, ClientInfo)        // delivery error hook
+	onPanic  func(interface{}, ClientInfo)  // panic recovery hook
+
+	onConnect  func(*http.Request, string) []Event              // initial events hook
+	onLastID   func(string) []Event                             // reconnect catch-up hook
+	greeting   string                                           // greeting event name
+	onResponse func(*http.Request, ClientInfo, http.Header) int // response status hook
+
+	middleware []Middleware                      // outgoing events middleware
+	wrappers   []func(http.Handler) http.Handler // stream requests middleware
+	auditLog   *auditLog                         // broadcast events log
+	encoders   []encoder                         // stream compressions
+	noCompress func(*http.Request) bool          // compression opt-out of the connection
+	timing     time.Duration                     // streaming metrics report interval
+	tuneConn   func(net.Conn)                    // stream connection tuning
+
+	handshakeTimeout time.Duration                     // connection setup time limit
+	retryPolicy      func(connected int) time.Duration // reconnection delay policy
+	affinity         *http.Cookie                      // instance affinity cookie
+	joined           chan struct{}                     // closed when a client joins
+	onChange         func(connected int)               // clients count change hook
+	changes          changes                           // queued change hook calls
+	peers            *PeerCounts                       // clients of the other instances
+	maintenance      int32                             // maintenance mode flag
+	store            EventStore                        // replayed events store
+	acks             acks                              // events waiting for acknowledgement
+	conns            map[string][]*client              // connections by client identity
+	connLimit        int                               // maximum connections per client
+	connPolicy       ConnLimitPolicy                   // connections limit policy
+	drainTimeout     time.Duration                     // queued events delivery time on shutdown
+	replayMu         sync.Mutex                        // orders the stored events and joins
+	closing          chan struct{}                     // closed to stop the recurring events
+	throttleParam    string                            // connection throttle query parameter
+	throttleMax      time.Duration                     // maximum connection throttle
+	maxSize          int                               // maximum encoded event size
+	metadata         bool                              // events metadata comments
+	frames           frameCache                        // encoded events sent again
+	clock            Clock                             // time source
+	heartbeat        time.Duration                     // heartbeat comments interval
+	classify         func(r *http.Request) string      // connection class extractor
+	classes          map[string]ClientClass            // delivery profiles by class
+	retainKey        func(Event) string                // retained events key
+	retained         map[string]Event                  // retained events by key
+	retainOrder      []string                          // retained events keys in order
+	accessLog        func(ConnectionLog)               // finished connections log
+	traceContext     func(context.Context) string      // publishing trace context
+	envelope         bool                              // events data envelope
+	cursors          *cursors                          // last stored events by client identity
+	mirrors          []*mirror                         // targets of the mirrored events
+	header           http.Header                       // stream response headers
+	padding          int                               // stream start padding size
+	retryMin         time.Duration                     // minimum reconnection delay
+	retryMax         time.Duration                     // maximum reconnection delay
+	flushPolicy      FlushPolicy                       // connections flush policy
+	labels           bool                              // connections pprof labels
+	limits           *softLimits                       // soft limits warnings
+	mu               sync.RWMutex
+}
+
+// Option configures the Server.
+type Option func(*Server)
+
+// WithClientID sets the function used to get the stable client identity from
+// the request: session cookie, header or query parameter. Several connections
+// may share the same identity (e.g. browser tabs of the same user). If the
+// function is not set or returns an empty string, a random identifier is
+// assigned to the connection.
+func WithClientID(fn func(r *http.Request) string) Option {
+	return func(s *Server) {
+		s.clientID = fn
+	}
+}
+
+// WithScopes sets the function used to get the scopes granted to the client
+// of the request, e.g. from the verified session claims. Events with the Scope
+// set are delivered only to the clients having that scope, so the
+// authorization decision stays on the server side.
+func WithScopes(fn func(r *http.Request) []string) Option {
+	return func(s *Server) {
+		s.scopes = fn
+	}
+}
+
+// WithChannels sets the function used to get the names of the channels the
+// client of the request subscribes to, e.g. from the query or path
+// parameters. Events published to a channel are delivered only to its
+// subscribers, while events without the channel are delivered to all clients.
+//
+// The channels are hierarchical with the dot separated segments, such as
+// "orders.eu.new", and the clients may subscribe to the patterns: the "*"
+// segment matches any one segment and the "#" at the end matches the rest of
+// the channel, so "orders.*" follows "orders.new" and "orders.paid", and
+// "metrics.host-#" follows "metrics.host-1.cpu".
+func WithChannels(fn func(r *http.Request) []string) Option {
+	return func(s *Server) {
+		s.channels = fn
+	}
+}
+
+// SlowClientPolicy defines what to do with the event when the queue of the
+// client is full.
+type SlowClientPolicy int
+
+const (
+	// SlowClientBlock waits until the client takes the event from the queue.
+	SlowClientBlock SlowClientPolicy = iota
+	// SlowClientDrop drops the event for that client.
+	SlowClientDrop
+	// SlowClientDisconnect drops the event and disconnects the client.
+	SlowClientDisconnect
+)
+
+// WithBufferSize sets the size of the queue of events waiting for delivery to
+// each client. By default the queue is not used at all and the server waits
+// for every client to take the event.
+func WithBufferSize(size int) Option {
+	return func(s *Server) {
+		s.buffer = size
+	}
+}
+
+// WithSlowClientPolicy sets what to do with the event when the queue of the
+// client is full. By default the server waits for the client.
+func WithSlowClientPolicy(policy SlowClientPolicy) Option {
+	return func(s *Server) {
+		s.policy = policy
+	}
+}
+
+// New returns a new Server configured with the given options. The zero value
+// of the Server is ready to use too.
+func New(opts ...Option) *Server {
+	s := new(Server)
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.shareClock()
+	return s
+}
+
+// ClientInfo describes the connected client.
+type ClientInfo struct {
+	ID         string   // client identity
+	RemoteAddr string   // network address of the client
+	Proto      string   // protocol of the connection, such as "HTTP/2.0"
+	Scopes     []string // scopes granted to the client
+	Channels   []string // channels the client subscribes to
+	Class      string   // connection class (see WithClientClasses)
+	Languages  []string // preferred languages of the Accept-Language header
+}
+
+// HasScope reports whether the client is granted the given scope.
+func (i ClientInfo) HasScope(scope string) bool {
+	for _, s := range i.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribed reports whether the client subscribes to the given channel
+// directly or with the pattern (see WithChannels).
+func (i ClientInfo) Subscribed(channel string) bool {
+	for _, pattern := range i.Channels {
+		if matchChannel(pattern, channel) {
+			return true
+		}
+	}
+	return false
+}
+
+// client describes the connection registered on the server.
+type client struct {
+	seq      uint64 // events sequence, first for the atomic alignment
+	info     ClientInfo
+	channels *channelTrie                 // subscription patterns of the info
+	names    map[string]bool              // event names received, nil for all
+	filter   func(Event, ClientInfo) bool // events filter of the mount
+	initial  []string                     // messages sent before the broadcast ones
+	cursor   string                       // last stored event of the initial messages
+	messages chan message                 // channel for receiving events
+	control  chan string                  // comments
+	retry    atomic.Pointer[string]       // latest retry directive not written
+	retryDue chan struct{}                // signals the retry directive
+	done     chan struct{}                // closed to disconnect the client
+	once     sync.Once
+	server   atomic.Pointer[Server] // server the client is registered with
+
+	draining  chan struct{} // closed to close after the queued events
+	drainOnce sync.Once
+	stopped   chan struct{} // closed when the connection is served
+
+	queueWarned atomic.Bool // queue depth warning is issued
+}
+
+// message is the encoded event queued for the client.
+type message struct {
+	data    string
+	queued  time.Time
+	expires time.Time // zero if the event does not expire
+	cursor  string    // stored event identifier moving the client cursor
+}
+
+// setRetry replaces the retry directive not written yet, so the latest one is
+// written even if the client does not keep up.
+func (c *client) setRetry(data string) {
+	c.retry.Store(&data)
+	select {
+	case c.retryDue <- struct{}{}:
+	default: // already signaled
+	}
+}
+
+// disconnect signals the client connection to be closed.
+func (c *client) disconnect() {
+	c.once.Do(func() { close(c.done) })
+}
+
+// newClientID returns a new random client identifier.
+func newClientID() string {
+	var b [12]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// clientInfo returns the information about the client of the request.
+func (s *Server) clientInfo(r *http.Request) ClientInfo {
+	var id string
+	if s.clientID != nil {
+		id = s.clientID(r)
+	}
+	if id == "" {
+		id = newClientID()
+	}
+	var scopes, channels []string
+	if s.scopes != nil {
+		scopes = s.scopes(r)
+	}
+	if s.channels != nil {
+		channels = s.channels(r)
+	}
+	var class string
+	if s.classify != nil {
+		class = s.classify(r)
+	}
+	return ClientInfo{
+		ID:         id,
+		RemoteAddr: r.RemoteAddr,
+		Proto:      r.Proto,
+		Scopes:     scopes,
+		Channels:   channels,
+		Class:      class,
+		Languages:  parseAcceptLanguage(r.Header.Get("Accept-Language")),
+	}
 }
 
 // Connected return number of connected clients.
@@ -26,89 +303,424 @@ func (s *Server) Connected() int {
 	return len(s.clients)
 }
 
-var (
-	pool            = sync.Pool{New: func() interface{} { return new(strings.Builder) }}
-	newlineReplacer = strings.NewReplacer("\n", "\\n")
-)
+var newlineReplacer = strings.NewReplacer("\n", "\\n", "\r", "\\r")
+
+// lineBreaks normalizes the line breaks of the text to the newlines.
+var lineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// Event describes the server-sent event.
+type Event struct {
+	ID      string // event identifier
+	Name    string // event type name
+	Data    string // event data
+	Scope   string // scope required by the client to receive the event
+	Channel string // channel the event is published to
+	Trace   string // trace context of the publisher (see Traced)
+
+	Priority Priority // delivery priority, not sent to the client
+	QoS      QoS      // delivery guarantee level, not sent to the client
+
+	// TTL, if set, is the time the event may wait in the client queue: the
+	// stale events are dropped instead of delivered late (see Stats.Expired)
+	TTL time.Duration
+}
+
+// encode returns the event in text stream format. The frame is written to
+// the buffer of the exact size without the intermediate strings, so the
+// returned string is the only allocation for the events without the signature.
+func (s *Server) encode(e Event) string {
+	return s.format(s.envelop(e))
+}
+
+// format returns the event already wrapped in the envelope in text stream
+// format.
+func (s *Server) format(e Event) string {
+	var sig string
+	if s.signKey != nil {
+		signed := e
+		signed.Channel = "" // not written to the stream
+		sig = Sign(s.signKey, signed)
+	}
+	size := 0
+	if e.Name != "" {
+		size += len("event: \n") + len(e.Name) + strings.Count(e.Name, "\n")
+	}
+	if e.Data != "" {
+		size += linesSize("data: ", e.Data)
+	}
+	if e.Trace != "" {
+		size += len("trace: \n") + len(e.Trace) + strings.Count(e.Trace, "\n")
+	}
+	if sig != "" {
+		size += len("sig: \n") + len(sig)
+	}
+	if e.ID != "" {
+		size += len("id: \n") + len(e.ID) + strings.Count(e.ID, "\n")
+	}
+
+	var buf strings.Builder
+	buf.Grow(size)
+	if e.Name != "" {
+		writeField(&buf, "event: ", e.Name)
+	}
+	if e.Data != "" {
+		writeLines(&buf, "data: ", e.Data)
+	}
+	if e.Trace != "" {
+		writeField(&buf, "trace: ", e.Trace)
+	}
+	if sig != "" {
+		writeField(&buf, "sig: ", sig)
+	}
+	if e.ID != "" {
+		writeField(&buf, "id: ", e.ID)
+	}
+	return buf.String()
+}
+
+// writeField writes the single line field with the line breaks of the value
+// escaped.
+func writeField(buf *strings.Builder, field, value string) {
+	buf.WriteString(field)
+	for {
+		i := strings.IndexAny(value, "\r\n")
+		if i < 0 {
+			break
+		}
+		buf.WriteString(value[:i])
+		if value[i] == '\r' {
+			buf.WriteString("\\r")
+		} else {
+			buf.WriteString("\\n")
+		}
+		value = value[i+1:]
+	}
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// writeLines writes the field for every line of the text, scanning for the
+// newlines without splitting the text.
+func writeLines(buf *strings.Builder, field, text string) {
+	if strings.IndexByte(text, '\r') >= 0 {
+		text = lineBreaks.Replace(text) // the line breaks of the stream
+	}
+	for {
+		i := strings.IndexByte(text, '\n')
+		if i < 0 {
+			buf.WriteString(field)
+			buf.WriteString(text)
+			buf.WriteByte('\n')
+			return
+		}
+		buf.WriteString(field)
+		buf.WriteString(text[:i])
+		buf.WriteByte('\n')
+		text = text[i+1:]
+	}
+}
+
+// linesSize returns the size of the text written with writeLines.
+func linesSize(field, text string) int {
+	if strings.IndexByte(text, '\r') >= 0 {
+		text = lineBreaks.Replace(text)
+	}
+	lines := strings.Count(text, "\n") + 1
+	return lines*len(field) + len(text) + 1
+}
+
+// allowed reports whether the client is allowed to receive the event.
+func (e Event) allowed(c *client) bool {
+	return (e.Scope == "" || c.info.HasScope(e.Scope)) &&
+		(e.Channel == "" || c.subscribed(e.Channel)) &&
+		c.accepts(e.Name) &&
+		(c.filter == nil || c.filter(e, c.info))
+}
+
+// Send sends the event to all connected clients allowed to receive it.
+func (s *Server) Send(e Event) {
+	_, _ = s.sendTo(context.Background(), e, nil)
+}
+
+// sendTo sends the event to the connected clients accepted by the match and
+// allowed to receive it. A nil match accepts all clients. It returns
+// ErrTooLarge if the encoded event exceeds the maximum size, or the context
+// error if it is done before all clients took the event: the remaining ones
+// do not get it.
+func (s *Server) sendTo(ctx context.Context, e Event, match func(*client) bool) (SendResult, error) {
+	t, seq := s.stamp() // the envelope of the event encoded again
+	data := s.encodeAt(e, t, seq)
+	if err := s.checkSize(data); err != nil {
+		s.onWriteError(err, ClientInfo{})
+		return SendResult{}, err
+	}
+	if e.QoS != QoSFireAndForget || s.retainKey != nil {
+		s.replayMu.Lock() // the replaying clients join before or after
+		defer s.replayMu.Unlock()
+	}
+	if stored := s.persist(e); stored != e {
+		e, data = stored, s.encodeAt(stored, t, seq)
+	}
+	if s.resent() {
+		s.frames.put(frameKeyOf(e), data)
+	}
+	s.retain(e)
+	s.sent.Add(1)
+	s.watchMemory()
+	var result SendResult
+	var canceled error // context error of the clients not getting the event
+	s.each(func(c *client) {
+		if match == nil || match(c) {
+			ok, err := s.sendEvent(ctx, c, e, data)
+			if err != nil && err == ctx.Err() {
+				canceled = err
+			}
+			result.add(c.info, ok, err)
+			if ok && err == nil {
+				s.track(e, c.info)
+			}
+		}
+	})
+	s.reportTooLarge(result)
+	s.audit(e, result.Delivered)
+	if match == nil {
+		s.mirror(e)
+	}
+	return result, canceled
+}
+
+// sendEvent delivers the event to the client if it is allowed and accepted by
+// the middleware. It reports whether the event is sent to the client and the
+// error of its queueing, ErrTooLarge if the event encoded for the client
+// exceeds the maximum size. The data is the event already encoded, used if the
+// middleware does not change the event.
+func (s *Server) sendEvent(ctx context.Context, c *client, e Event, data string) (bool, error) {
+	if data, ok := s.render(c, e, data); ok {
+		data = s.withMetadata(c, e, data)
+		if err := s.checkSize(data); err != nil {
+			return true, err
+		}
+		return true, s.deliver(ctx, c, data, e)
+	}
+	return false, nil
+}
+
+// render returns the event encoded for the client if it is allowed and
+// accepted by the middleware. The data is the event already encoded or empty.
+func (s *Server) render(c *client, e Event, data string) (string, bool) {
+	if !e.allowed(c) {
+		return "", false
+	}
+	out, ok := s.apply(e, c.info)
+	if !ok {
+		return "", false
+	}
+	if out != e || data == "" {
+		data = s.encode(out)
+	}
+	return data, true
+}
+
+// NewEvent returns the event with the given data encoded as JSON. The strings,
+// byte slices and errors are used as is.
+func NewEvent(id, name string, v interface{}) (Event, error) {
+	data, err := marshal(v)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{ID: id, Name: name, Data: data}, nil
+}
 
 // Event sends an event with the given data encoded as JSON to all connected
 // clients.
 func (s *Server) Event(id, name string, v interface{}) error {
-	// converting the data to the JSON format, if necessary
-	var data string
+	e, err := NewEvent(id, name, v)
+	if err != nil {
+		return err
+	}
+	_, err = s.sendTo(context.Background(), e, nil)
+	return err
+}
+
+// EventTo sends an event with the given data encoded as JSON to all
+// connections of the client with the given identity. It returns ErrNoClients
+// if the client is not connected.
+func (s *Server) EventTo(clientID, id, name string, v interface{}) error {
+	e, err := NewEvent(id, name, v)
+	if err != nil {
+		return err
+	}
+	var matched bool
+	_, err = s.sendTo(context.Background(), e, func(c *client) bool {
+		if c.info.ID != clientID {
+			return false
+		}
+		matched = true
+		return true
+	})
+	if err == nil && !matched {
+		err = ErrNoClients
+	}
+	return err
+}
+
+// marshal returns the data converted to the JSON format, if necessary.
+func marshal(v interface{}) (string, error) {
 	switch v := v.(type) {
 	case nil:
+		return "", nil
 	case string:
-		data = v
+		return v, nil
 	case []byte:
-		data = string(v)
+		return string(v), nil
 	case json.RawMessage:
-		data = string(v)
+		return string(v), nil
 	case error:
-		data = v.Error()
+		return v.Error(), nil
 	default:
-		d, err := json.Marshal(v)
+		data, err := json.Marshal(v)
 		if err != nil {
-			return err
+			return "", err
 		}
-		data = string(d)
-	}
-
-	buf := pool.Get().(*strings.Builder)
-	buf.Reset()
-
-	if name != "" {
-		fmt.Fprintln(buf, "event:", newlineReplacer.Replace(name))
-	}
-	if data != "" {
-		for _, line := range strings.Split(data, "\n") {
-			fmt.Fprintln(buf, "data:", line)
-		}
-	}
-	if id != "" {
-		fmt.Fprintln(buf, "id:", newlineReplacer.Replace(id))
+		return string(data), nil
 	}
-
-	s.send(buf.String())
-
-	pool.Put(buf)
-
-	return nil
 }
 
 // Comment sends an comment with the given text to all connected clients.
 func (s *Server) Comment(text string) {
-	buf := pool.Get().(*strings.Builder)
-	buf.Reset()
+	var buf strings.Builder
+	buf.Grow(linesSize(": ", text))
+	writeLines(&buf, ": ", text)
+	s.sendControl(buf.String())
+}
 
-	for _, line := range strings.Split(text, "\n") {
-		fmt.Fprintln(buf, ":", line)
+// Retry sends all clients an indication of the delay in restoring the connection.
+// The delay is rounded up to the milliseconds and kept within the retry limits
+// (see WithRetryLimits). The negative delay is not sent: ErrInvalidRetry is
+// reported to the WithOnError hook.
+func (s *Server) Retry(d time.Duration) {
+	if d < 0 {
+		s.onWriteError(ErrInvalidRetry, ClientInfo{})
+		return
 	}
+	data := retryField(s.clampRetry(d))
+	s.each(func(c *client) { c.setRetry(data) })
+}
 
-	s.send(buf.String())
+// send sends data to all registered clients accepted by the filter. A nil
+// filter accepts all clients.
+func (s *Server) send(data string, filter func(*client) bool) {
+	s.each(func(c *client) {
+		if filter == nil || filter(c) {
+			s.deliver(context.Background(), c, data, Event{})
+		}
+	})
+}
 
-	pool.Put(buf)
+// each calls the function for all registered clients.
+func (s *Server) each(fn func(*client)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for c := range s.clients {
+		s.call(c, fn)
+	}
 }
 
-// Retry sends all clients an indication of the delay in restoring the connection.
-func (s *Server) Retry(d time.Duration) {
-	s.send(fmt.Sprintln("retry:", int64(d)/1000/1000))
+// call calls the function for the client. The panic is recovered, so it does
+// not stop the delivery to other clients.
+func (s *Server) call(c *client, fn func(*client)) {
+	defer func() {
+		if v := recover(); v != nil {
+			s.panicked(v, c.info)
+		}
+	}()
+	fn(c)
 }
 
-// send sends data to all registered customers.
-func (s *Server) send(data string) {
-	s.mu.RLock()
-	for client := range s.clients {
-		client <- data
+// deliver puts the data of the event to the queue of the client according to
+// the slow client policy and the event priority and returns the error if it is
+// not queued. The outcome is counted in the statistics.
+func (s *Server) deliver(ctx context.Context, c *client, data string, e Event) error {
+	err := s.enqueue(ctx, c, data, e)
+	switch err {
+	case nil:
+		s.delivered.Add(1)
+	case errDropped, ErrSlowClient:
+		s.dropped.Add(1)
+	}
+	return err
+}
+
+// enqueue puts the data of the event to the queue of the client. The waiting
+// for the slow client stops when the context is done.
+func (s *Server) enqueue(ctx context.Context, c *client, data string, e Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	defer s.watchQueue(c)
+	m := message{data: data, queued: clockOrSystem(s.clock).Now(), cursor: s.cursorOf(e)}
+	if e.TTL > 0 {
+		m.expires = m.queued.Add(e.TTL)
+	}
+	if e.Priority == PriorityLow && c.underPressure() {
+		return errDropped
+	}
+	if s.policy == SlowClientBlock {
+		select {
+		case c.messages <- m:
+			return nil
+		case <-c.done:
+			return ErrClientGone
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if s.policy == SlowClientDrop && e.Priority == PriorityHigh {
+		wait := time.NewTimer(maxPriorityWait)
+		defer wait.Stop()
+		select {
+		case c.messages <- m:
+			return nil
+		case <-c.done:
+			return ErrClientGone
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wait.C:
+			c.disconnect()
+			return ErrSlowClient
+		}
+	}
+
+	select {
+	case c.messages <- m:
+		return nil
+	case <-c.done:
+		return ErrClientGone
+	default:
+		if s.policy == SlowClientDisconnect {
+			c.disconnect()
+			return ErrSlowClient
+		}
+		return errDropped
 	}
-	s.mu.RUnlock()
 }
 
 // Close closes the server and disconnect all clients.
+//
+// The server stays usable after Close: the new connections are accepted and
+// the events are sent to them, so the long-lived services toggling the
+// streaming off and on don't need to rewire the handlers referencing the
+// server. The browsers reconnect after the retry delay; send the longer Retry
+// before Close to make them back off. The recurring events (see Every) are
+// stopped.
 func (s *Server) Close() {
 	s.mu.Lock()
-	for client := range s.clients {
-		close(client)
+	for c := range s.clients {
+		c.disconnect()
+	}
+	if s.closing != nil {
+		close(s.closing)
+		s.closing = nil
 	}
 	s.mu.Unlock()
 }
@@ -116,11 +728,68 @@ func (s *Server) Close() {
 // mimetype specifies the data type for server events.
 const mimetype = "text/event-stream"
 
+// register adds the client to the broadcast set.
+func (s *Server) register(c *client) error {
+	var err error
+	s.changeClients(func() {
+		if !s.admit(c) {
+			err = errConnLimit
+			return
+		}
+		if s.clients == nil {
+			s.clients = make(map[*client]struct{})
+		}
+		s.clients[c] = struct{}{}
+		c.server.Store(s)
+		if s.joined != nil {
+			close(s.joined) // wake up the waiting for clients
+			s.joined = nil
+		}
+	})
+	return err
+}
+
+// unregister removes the client from the broadcast set of the server it is
+// registered with, which may be another one after Migrate.
+func (s *Server) unregister(c *client) {
+	for {
+		owner := c.server.Load()
+		if owner == nil {
+			owner = s
+		}
+		var found bool
+		owner.changeClients(func() {
+			if _, found = owner.clients[c]; found {
+				delete(owner.clients, c)
+				owner.release(c)
+			}
+		})
+		if found || c.server.Load() == owner || c.server.Load() == nil {
+			return
+		}
+	}
+}
+
 // ServeHTTP implements http.Handler interface.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+	s.serve(w, r, &handler{server: s})
+}
+
+// serveStream serves the events stream with the given mount options.
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request, h *handler) {
+	started := time.Now()
+	info := ClientInfo{RemoteAddr: r.RemoteAddr, Proto: r.Proto}
+	defer func() {
+		if v := recover(); v != nil {
+			s.panicked(v, info)
+		}
+	}()
+
+	if h.prepare(w, r) {
+		return // preflight request is served
+	}
+	if h.auth != nil && !h.auth(r) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 		return
 	}
 
@@ -131,45 +800,366 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotAcceptable)
 		return
 	}
+	if h.later.serve(w, r) {
+		return
+	}
+
+	if s.Maintenance() {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	if s.accepts != nil {
+		if ok, retry := s.accepts.allow(r, clockOrSystem(s.clock).Now()); !ok {
+			tooManyRequests(w, retry)
+			return
+		}
+	}
 
 	w.Header().Set("Content-Type", mimetype)
 	w.Header().Set("Cache-Control", "no-cache")
+	s.streamHeaders(w)
+	s.setAffinity(w, r)
+	if r.ProtoMajor >= 2 {
+		// the connection-specific headers are prohibited in HTTP/2 and the
+		// multiplexed streams are flushed after each event anyway
+		w.Header().Del("Connection")
+		w.Header().Del("Keep-Alive")
+	}
 
-	messages := make(chan string) // channel for receiving events
-	s.mu.Lock()
-	if s.clients == nil {
-		s.clients = make(map[chan string]struct{})
+	setup, cancel := s.handshake(r, started)
+	defer cancel()
+	info = s.clientInfo(setup)
+	defer s.label(r.Context(), info)()
+	c := &client{
+		info:     info,
+		channels: newChannelTrie(info.Channels),
+		filter:   h.filter,
+		messages: make(chan message, s.buffer),
+		control:  make(chan string, controlLane),
+		retryDue: make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		draining: make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	defer close(c.stopped)
+	s.pad(c)
+	s.adviseRetry(c)
+	s.greet(c)
+	s.connectEvents(setup, c)
+	joined := errNotJoined // registration result of the client
+	register := func(c *client) { joined = s.joinReplay(setup, c) }
+	if h.join != nil {
+		if err := h.join(c, register); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		register(c)
+	}
+	switch joined {
+	case nil:
+	case errConnLimit:
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	default:
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	defer func() {
+		c.disconnect()
+		s.unregister(c)
+	}()
+
+	s.tune(r)
+	stream := newStreamWriter(w)
+	var out io.Writer = stream
+	flush := stream.Flush
+	if enc := s.compressor(w, r); enc != nil {
+		defer enc.Close()
+		out = enc
+		flush = func() error {
+			if err := enc.Flush(); err != nil {
+				return err
+			}
+			return stream.Flush()
+		}
+	}
+	var events int   // events written to the connection
+	var failed error // write error closing the connection
+	if s.accessLog != nil {
+		counted := &countingWriter{w: out}
+		out = counted
+		defer func() { s.logAccess(r, c, started, events, counted.n, failed) }()
+	}
+	// fail reports the write error closing the connection
+	fail := func(err error) {
+		failed = err
+		s.onWriteError(err, s.current(c))
+	}
+
+	var timing *timings // streaming metrics of the client
+	if s.timing > 0 {
+		w.Header().Set("Server-Timing", fmt.Sprintf("setup;dur=%.3f", ms(time.Since(started))))
+		timing = new(timings)
+	}
+
+	s.writeHeader(w, setup, info)
+	// the initial messages are sent before any broadcast ones
+	for _, data := range c.initial {
+		if _, err := fmt.Fprintln(out, data); err != nil {
+			failed = err
+			return
+		}
+		events++
+	}
+	if s.handshakeTimeout > 0 {
+		_ = stream.SetWriteDeadline(started.Add(s.handshakeTimeout))
+	}
+	if err := flush(); err != nil { // send the headers to the client right now
+		failed = err
+		return
+	}
+	s.advance(c, c.cursor)
+	if s.handshakeTimeout > 0 {
+		_ = stream.SetWriteDeadline(time.Time{})
 	}
-	s.clients[messages] = struct{}{}
-	s.mu.Unlock()
 
+	clock := clockOrSystem(s.clock)
+	var limit *limiter // outbound events rate limiter
+	if s.rate > 0 {
+		limit = newLimiter(s.rate, s.burst)
+	}
+	var writes *breaker // write failures of the client
+	if s.breaker != nil {
+		writes = newBreaker(s.breaker.window)
+	}
+	var tick <-chan time.Time // streaming metrics reports
+	if timing != nil {
+		ticker := time.NewTicker(s.timing)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	heartbeat := s.heartbeat
+	if d := s.class(info).Heartbeat; d > 0 {
+		heartbeat = d
+	}
+	var beat <-chan time.Time // heartbeats
+	if heartbeat > 0 {
+		ticker := clock.NewTicker(heartbeat)
+		defer ticker.Stop()
+		beat = ticker.C()
+	}
+	policy := s.flushing(info) // flush after every event if nil
+	var (
+		pending  int              // events written and not flushed
+		flushing Timer            // delayed flush of the pending events
+		flushDue <-chan time.Time // delayed flush is due
+	)
+	// flushed flushes the pending events
+	flushed := func() error {
+		pending = 0
+		if flushing != nil {
+			flushing.Stop()
+			flushing, flushDue = nil, nil
+		}
+		return flush()
+	}
+	// written flushes the written event as the flush policy says
+	written := func() error {
+		pending++
+		if policy == nil || policy.Flush(pending, len(c.messages)) {
+			return flushed()
+		}
+		if flushing == nil {
+			flushing = clock.NewTimer(policy.Delay())
+			flushDue = flushing.C()
+		}
+		return nil
+	}
+	// control writes the control message ahead of the queued events
+	control := func(data string) error {
+		if _, err := io.WriteString(out, data+"\n"); err != nil {
+			return err
+		}
+		return flushed()
+	}
+	delay := s.throttle(r, info, clock) // writes throttle of the connection
+	defer delay.stop()
+	var delayed <-chan time.Time // delayed write
+	// send writes the data to the client now or with the batch of the
+	// throttled connection when its interval is due
+	send := func(data string, now time.Time) error {
+		batch, ok := delay.hold(data, now)
+		if !ok {
+			delayed = delay.C
+			return nil
+		}
+		if _, err := io.WriteString(out, batch); err != nil {
+			return err
+		}
+		return written()
+	}
+	draining := c.draining     // shutdown of the server
 	done := r.Context().Done() // channel closure compound
-	var closed bool            // flag that channel is already closed
+	// finish writes the events held by the throttle before the close
+	finish := func() {
+		if delay != nil && delay.batch.Len() > 0 {
+			if _, err := io.WriteString(out, delay.release(clock.Now())); err == nil {
+				_ = flushed()
+			}
+		} else if pending > 0 {
+			_ = flushed()
+		}
+	}
+	// retry writes the latest retry directive, if not written yet
+	retry := func() error {
+		if data := c.retry.Swap(nil); data != nil {
+			return control(*data)
+		}
+		return nil
+	}
+	var (
+		messages = c.messages     // queued events, nil while one is held
+		held     message          // event waiting for the rate limit
+		limited  Timer            // rate limit wait of the held event
+		allowed  <-chan time.Time // held event is allowed by the rate limit
+	)
+	// deliver writes the event and reports whether the connection is served
+	// further
+	deliver := func(m message) bool {
+		if !m.expires.IsZero() && clock.Now().After(m.expires) {
+			s.expired.Add(1)
+			if draining == nil && len(c.messages) == 0 {
+				finish()
+				return false
+			}
+			return true
+		}
+
+		start := time.Now()
+		err := send(m.data, clock.Now())
+		if timing != nil {
+			timing.add(clock.Now().Sub(m.queued), time.Since(start))
+		}
+		if writes != nil && s.breaker.record(writes, time.Since(start), err) && err == nil {
+			err = ErrCircuitOpen
+		}
+		if err != nil {
+			fail(err)
+			return false
+		}
+		events++
+		s.advance(c, m.cursor)
+		if draining == nil && len(c.messages) == 0 {
+			finish()
+			return false // the queued events are delivered
+		}
+		return true
+	}
+	defer func() {
+		if flushing != nil {
+			flushing.Stop()
+		}
+		if limited != nil {
+			limited.Stop()
+		}
+	}()
 loop:
 	for {
 		select {
-		case data, ok := <-messages:
-			if !ok {
-				closed = true // bring the flag that the channel is already closed
+		case data := <-c.control: // the control lane goes first
+			if err := control(data); err != nil {
+				fail(err)
+				break loop
+			}
+			continue
+		case <-c.retryDue:
+			if err := retry(); err != nil {
+				fail(err)
+				break loop
+			}
+			continue
+		default:
+		}
+
+		select {
+		case data := <-c.control:
+			if err := control(data); err != nil {
+				fail(err)
+				break loop
+			}
+
+		case <-c.retryDue:
+			if err := retry(); err != nil {
+				fail(err)
+				break loop
+			}
+
+		case <-beat:
+			if err := control(heartbeatComment); err != nil {
+				fail(err)
+				break loop
+			}
+
+		case m := <-messages:
+			if limit != nil {
+				if d := limit.reserve(clock.Now()); d > 0 {
+					// the control messages are written while the event waits
+					held, messages = m, nil
+					limited = clock.NewTimer(d)
+					allowed = limited.C()
+					continue
+				}
+			}
+			if !deliver(m) {
+				break loop
+			}
+
+		case <-allowed:
+			limited, allowed, messages = nil, nil, c.messages
+			if !deliver(held) {
+				break loop
+			}
+
+		case <-draining:
+			draining = nil // drained when the queue is empty
+			if messages != nil && len(c.messages) == 0 {
+				finish()
+				break loop
+			}
+
+		case <-delayed:
+			delayed = nil
+			_, err := io.WriteString(out, delay.release(clock.Now()))
+			if err == nil {
+				err = written()
+			}
+			if err != nil {
+				fail(err)
 				break loop
 			}
 
-			if _, err := fmt.Fprintln(w, data); err != nil {
+		case <-flushDue:
+			flushing, flushDue = nil, nil
+			if err := flushed(); err != nil {
+				fail(err)
 				break loop
 			}
 
-			flusher.Flush() // forced reset buffer for departure
+		case <-tick:
+			if timing.n == 0 {
+				continue
+			}
+			if err := send(timing.report(), clock.Now()); err != nil {
+				fail(err)
+				break loop
+			}
 
+		case <-c.done:
+			break loop
 		case <-done:
 			break loop
 		}
 	}
-
-	s.mu.Lock()
-	delete(s.clients, messages)
-	s.mu.Unlock()
-
-	if !closed {
-		close(messages)
-	}
 }
@@ -6,84 +6,293 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
-	"os"
-	"os/signal"
 	"testing"
 	"time"
 )
 
 func TestSSE(t *testing.T) {
 	broker := new(Server)
+	ts := httptest.NewServer(broker)
+	defer ts.Close()
+	defer broker.Close()
 
-	go func() {
-		for range time.Tick(1 * time.Second) {
-			_ = broker.Event("", "timer", time.Now().Format("15:04:05"))
-		}
-	}()
+	r := connect(t, ts, nil)
+	waitConnected(t, broker, 1)
 
-	go func() {
-		for range time.Tick(7 * time.Second) {
-			broker.Comment("comment " + time.Now().Format("15:04:05"))
-		}
-	}()
-
-	go func() {
-		var id int
-		for range time.Tick(3 * time.Second) {
-			id++
-			_ = broker.Event(fmt.Sprintf("%04d", id), "event", &struct {
+	at := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	// the comments and the retry directives go ahead of the queued events,
+	// so each message is sent after the previous one is received
+	for _, step := range []struct {
+		send func()
+		want string
+	}{
+		{func() { _ = broker.Event("", "timer", at.Format("15:04:05")) },
+			"event: timer\ndata: 12:00:00\n"},
+		{func() { broker.Comment("comment\nsecond line") },
+			": comment\n: second line\n"},
+		{func() {
+			_ = broker.Event(fmt.Sprintf("%04d", 1), "event", &struct {
 				ID   int       `json:"id"`
 				Time time.Time `json:"time"`
 			}{
-				ID:   id,
-				Time: time.Now().Truncate(time.Second),
+				ID:   1,
+				Time: at,
 			})
+		}, "event: event\ndata: {\"id\":1,\"time\":\"2021-05-01T12:00:00Z\"}\nid: 0001\n"},
+		{func() { broker.Retry(1500 * time.Millisecond) },
+			"retry: 1500\n"},
+	} {
+		go step.send()
+		if msg := readMessage(t, r); msg != step.want {
+			t.Errorf("got %q, want %q", msg, step.want)
+		}
+	}
+
+	broker.Close()
+	if _, err := r.ReadString('\n'); err != io.EOF {
+		t.Errorf("stream is not closed: %v", err)
+	}
+}
+
+// connect opens the event stream of the test server with the given request
+// headers and returns the reader of the response body.
+func connect(t *testing.T, ts *httptest.Server, header http.Header) *bufio.Reader {
+	t.Helper()
+	req, err := http.NewRequest("GET", ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { res.Body.Close() })
+	if res.StatusCode != http.StatusOK {
+		t.Fatal("unexpected status:", res.Status)
+	}
+	return bufio.NewReader(res.Body)
+}
+
+// readMessage returns the next message of the stream without the trailing
+// empty line.
+func readMessage(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	var msg string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal("read error:", err)
+		}
+		if line == "\n" {
+			return msg
 		}
+		msg += line
+	}
+}
+
+// waitConnected waits until the server has the given number of clients.
+func waitConnected(t *testing.T, s *Server, n int) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); s.Connected() != n; {
+		if time.Now().After(deadline) {
+			t.Fatalf("connected %d clients, want %d", s.Connected(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestEventTo(t *testing.T) {
+	s := New(WithClientID(func(r *http.Request) string {
+		return r.Header.Get("X-User")
+	}))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	alice := connect(t, ts, http.Header{"X-User": {"alice"}})
+	bob := connect(t, ts, http.Header{"X-User": {"bob"}})
+	waitConnected(t, s, 2)
+
+	go func() {
+		_ = s.EventTo("alice", "1", "private", "hello alice")
+		_ = s.Event("2", "public", "hello all")
 	}()
 
-	ts := httptest.NewServer(broker)
-	// defer ts.Close()
+	want := "event: private\ndata: hello alice\nid: 1\n"
+	if msg := readMessage(t, alice); msg != want {
+		t.Errorf("alice got %q, want %q", msg, want)
+	}
+	want = "event: public\ndata: hello all\nid: 2\n"
+	if msg := readMessage(t, alice); msg != want {
+		t.Errorf("alice got %q, want %q", msg, want)
+	}
+	if msg := readMessage(t, bob); msg != want {
+		t.Errorf("bob got %q, want %q", msg, want)
+	}
+}
+
+func TestSendScope(t *testing.T) {
+	s := New(WithScopes(func(r *http.Request) []string {
+		return r.Header.Values("X-Scope")
+	}))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
 
-	fmt.Println("url:", ts.URL)
+	admin := connect(t, ts, http.Header{"X-Scope": {"user", "admin"}})
+	user := connect(t, ts, http.Header{"X-Scope": {"user"}})
+	waitConnected(t, s, 2)
+
+	go func() {
+		s.Send(Event{Name: "audit", Data: "secret", Scope: "admin"})
+		s.Send(Event{Name: "news", Data: "public"})
+	}()
+
+	want := "event: audit\ndata: secret\n"
+	if msg := readMessage(t, admin); msg != want {
+		t.Errorf("admin got %q, want %q", msg, want)
+	}
+	want = "event: news\ndata: public\n"
+	if msg := readMessage(t, admin); msg != want {
+		t.Errorf("admin got %q, want %q", msg, want)
+	}
+	if msg := readMessage(t, user); msg != want {
+		t.Errorf("user got %q, want %q", msg, want)
+	}
+}
+
+func TestSlowClientDisconnect(t *testing.T) {
+	s := New(WithBufferSize(1), WithSlowClientPolicy(SlowClientDisconnect))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
 
 	req, _ := http.NewRequest("GET", ts.URL, nil)
 	req.Header.Set("Accept", "text/event-stream")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	waitConnected(t, s, 1)
 
-	client := ts.Client()
-	// client.Transport = &logTransport{http.DefaultTransport}
+	// the client does not read, so the queue overflows sooner or later
+	data := string(make([]byte, 1<<16))
+	for i := 0; i < 1000 && s.Connected() > 0; i++ {
+		s.Send(Event{Data: data})
+	}
+	waitConnected(t, s, 0)
+}
+
+func TestSendChannel(t *testing.T) {
+	s := New(WithChannels(func(r *http.Request) []string {
+		return r.URL.Query()["channel"]
+	}))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
 
-	res, err := client.Do(req)
+	req, _ := http.NewRequest("GET", ts.URL+"?channel=news&channel=sport", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := ts.Client().Do(req)
 	if err != nil {
-		t.Error("http client error", err)
+		t.Fatal(err)
 	}
-	fmt.Println(res.Status)
+	defer res.Body.Close()
+	subscriber := bufio.NewReader(res.Body)
+	other := connect(t, ts, nil)
+	waitConnected(t, s, 2)
 
 	go func() {
-		r := bufio.NewReader(res.Body)
-		for {
-			s, err := r.ReadString('\n')
-			if err != nil {
-				if err != io.EOF {
-					t.Error("event source error", err)
-				}
-				break
-			}
-			fmt.Print(s)
-		}
-		res.Body.Close()
+		s.Send(Event{Data: "goal", Channel: "sport"})
+		s.Send(Event{Data: "rain", Channel: "weather"})
+		s.Send(Event{Data: "all"})
 	}()
 
-	fmt.Println("waiting...")
-	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, os.Interrupt)
+	if msg := readMessage(t, subscriber); msg != "data: goal\n" {
+		t.Errorf("subscriber got %q", msg)
+	}
+	if msg := readMessage(t, subscriber); msg != "data: all\n" {
+		t.Errorf("subscriber got %q", msg)
+	}
+	if msg := readMessage(t, other); msg != "data: all\n" {
+		t.Errorf("other client got %q", msg)
+	}
+}
+
+func TestReopenAfterClose(t *testing.T) {
+	s := New()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	s.Close()
+	waitConnected(t, s, 0)
 
-	select {
-	case <-signalChan:
-	case <-time.After(time.Second * 25):
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	go s.Send(Event{Data: "again"})
+	if msg := readMessage(t, r); msg != "data: again\n" {
+		t.Errorf("message %q", msg)
 	}
+}
 
-	broker.Close()
-	ts.Close()
+func TestEncodeFrame(t *testing.T) {
+	s := New()
+	for _, test := range []struct {
+		e    Event
+		want string
+	}{
+		{Event{Data: "data"}, "data: data\n"},
+		{Event{ID: "1", Name: "order", Data: "a\n\nb\n"},
+			"event: order\ndata: a\ndata: \ndata: b\ndata: \nid: 1\n"},
+		{Event{ID: "1\n2", Name: "a\nb"}, "event: a\\nb\nid: 1\\n2\n"},
+		{Event{}, ""},
+	} {
+		if data := s.encode(test.e); data != test.want {
+			t.Errorf("encoded %q, want %q", data, test.want)
+		}
+	}
+
+	e := Event{ID: "1", Name: "order", Data: "line 1\nline 2"}
+	if n := testing.AllocsPerRun(100, func() { s.encode(e) }); n != 1 {
+		t.Errorf("%v allocations per encoding", n)
+	}
+}
 
-	fmt.Println("the end")
+func BenchmarkEncode(b *testing.B) {
+	s := New()
+	e := Event{ID: "42", Name: "order", Data: `{"id":42,"items":["a","b"]}` + "\n" + `{"total":5}`}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.encode(e)
+	}
+}
+
+func BenchmarkEvent(b *testing.B) {
+	s := New()
+	c := &client{messages: make(chan message, 64), done: make(chan struct{})}
+	s.clients = map[*client]struct{}{c: {}}
+	go func() {
+		for {
+			select {
+			case <-c.messages:
+			case <-c.done:
+				return
+			}
+		}
+	}()
+	defer c.disconnect()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := s.Event("42", "order", `{"id":42}`); err != nil {
+			b.Fatal(err)
+		}
+	}
 }
@@ -0,0 +1,164 @@
+package ssetest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mdigger/sse"
+)
+
+// Clock is the fake sse.Clock for the tests of the time-driven behavior: the
+// time stands still until Advance moves it, firing the timers and the tickers
+// due in order. Pass it to the server with sse.WithClock and to the client
+// with sse.WithClientClock.
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer  // active timers
+	changed chan struct{} // closed when the timers are added
+}
+
+// NewClock returns the fake clock set to the given time.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now, changed: make(chan struct{})}
+}
+
+// Now implements sse.Clock interface.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer implements sse.Clock interface.
+func (c *Clock) NewTimer(d time.Duration) sse.Timer {
+	return c.add(&fakeTimer{ch: make(chan time.Time, 1)}, d)
+}
+
+// NewTicker implements sse.Clock interface. It panics if the interval is not
+// positive, as time.NewTicker does.
+func (c *Clock) NewTicker(d time.Duration) sse.Ticker {
+	if d <= 0 {
+		panic("ssetest: non-positive interval for NewTicker")
+	}
+	return fakeTicker{c.add(&fakeTimer{ch: make(chan time.Time, 1), period: d}, d)}
+}
+
+// AfterFunc implements sse.Clock interface.
+func (c *Clock) AfterFunc(d time.Duration, f func()) sse.Timer {
+	return c.add(&fakeTimer{fn: f}, d)
+}
+
+// add schedules the timer after the duration.
+func (c *Clock) add(t *fakeTimer, d time.Duration) *fakeTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t.clock = c
+	t.when = c.now.Add(d)
+	c.timers = append(c.timers, t)
+	close(c.changed)
+	c.changed = make(chan struct{})
+	return t
+}
+
+// Advance moves the time forward by the duration and fires the timers and the
+// tickers due meanwhile in order. As with the real tickers, the ticks are
+// dropped while the previous one is not received.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	until := c.now.Add(d)
+	for {
+		sort.SliceStable(c.timers, func(i, j int) bool {
+			return c.timers[i].when.Before(c.timers[j].when)
+		})
+		if len(c.timers) == 0 || c.timers[0].when.After(until) {
+			break
+		}
+		t := c.timers[0]
+		c.now = t.when
+		if t.period > 0 {
+			t.when = t.when.Add(t.period)
+		} else {
+			c.timers = c.timers[1:]
+		}
+		if t.fn != nil {
+			go t.fn()
+			continue
+		}
+		select {
+		case t.ch <- c.now:
+		default:
+		}
+	}
+	c.now = until
+}
+
+// Timers returns the number of the active timers and tickers.
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// WaitTimers waits until at least n timers and tickers are active, so the
+// code under the test is waiting for the time to advance. It returns false if
+// they are not created within the given real time.
+func (c *Clock) WaitTimers(n int, within time.Duration) bool {
+	timer := time.NewTimer(within)
+	defer timer.Stop()
+	for {
+		c.mu.Lock()
+		active, changed := len(c.timers), c.changed
+		c.mu.Unlock()
+		if active >= n {
+			return true
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return false
+		}
+	}
+}
+
+// fakeTimer is the timer, the ticker or the function call of the Clock.
+type fakeTimer struct {
+	clock  *Clock
+	when   time.Time     // the next firing time
+	period time.Duration // ticker interval or zero
+	ch     chan time.Time
+	fn     func()
+}
+
+// C implements sse.Timer interface. It returns nil for the timer of
+// AfterFunc.
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop implements sse.Timer interface. It returns false if the timer is
+// already fired or stopped.
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, active := range c.timers {
+		if active == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fakeTicker is the ticker of the Clock.
+type fakeTicker struct {
+	*fakeTimer
+}
+
+// Stop implements sse.Ticker interface.
+func (t fakeTicker) Stop() {
+	t.fakeTimer.Stop()
+}
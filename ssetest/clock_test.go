@@ -0,0 +1,146 @@
+package ssetest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mdigger/sse"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewClock(start)
+	timer := clock.NewTimer(time.Second)
+	ticker := clock.NewTicker(300 * time.Millisecond)
+	called := make(chan struct{})
+	clock.AfterFunc(2*time.Second, func() { close(called) })
+	if n := clock.Timers(); n != 3 {
+		t.Errorf("%d timers, want 3", n)
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	select {
+	case <-timer.C():
+		t.Error("timer fired early")
+	default:
+	}
+	if now := <-ticker.C(); !now.Equal(start.Add(300 * time.Millisecond)) {
+		t.Errorf("tick at %v", now)
+	}
+
+	clock.Advance(time.Second)
+	if now := <-timer.C(); !now.Equal(start.Add(time.Second)) {
+		t.Errorf("timer fired at %v", now)
+	}
+	if timer.Stop() {
+		t.Error("fired timer is stopped")
+	}
+	<-ticker.C() // the ticks are dropped while not received
+	select {
+	case <-ticker.C():
+		t.Error("dropped tick received")
+	default:
+	}
+	ticker.Stop()
+
+	clock.Advance(time.Second)
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Error("function is not called")
+	}
+	if now := clock.Now(); !now.Equal(start.Add(2500 * time.Millisecond)) {
+		t.Errorf("now %v", now)
+	}
+	if n := clock.Timers(); n != 0 {
+		t.Errorf("%d timers left", n)
+	}
+}
+
+func TestClockEvery(t *testing.T) {
+	clock := NewClock(time.Now())
+	s := sse.New(sse.WithClock(clock))
+	defer s.Close()
+	stream := Connect(t, s)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.WaitForClients(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := s.Every(time.Hour, func() (sse.Event, bool) {
+		return sse.Event{Name: "tick", Data: "1"}, true
+	})
+	defer stop()
+	stream.ExpectNoEvent(t, 50*time.Millisecond)
+	clock.Advance(time.Hour)
+	stream.ExpectEvent(t, "tick", time.Second)
+}
+
+func TestClockReconnect(t *testing.T) {
+	var connects int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&connects, 1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "id: %d\ndata: %d\n\n", n, n)
+	}))
+	defer ts.Close()
+
+	clock := NewClock(time.Now())
+	c := sse.NewClient(ts.URL, sse.WithReconnectDelay(time.Hour), sse.WithClientClock(clock))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := c.Events(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := <-events; e.Data != "1" {
+		t.Fatalf("first event %q", e.Data)
+	}
+	if !clock.WaitTimers(1, time.Second) {
+		t.Fatal("client does not wait for reconnection")
+	}
+	if n := atomic.LoadInt32(&connects); n != 1 {
+		t.Errorf("reconnected before the delay: %d connections", n)
+	}
+	clock.Advance(time.Hour)
+	select {
+	case e := <-events:
+		if e.Data != "2" {
+			t.Errorf("second event %q", e.Data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("client is not reconnected")
+	}
+	if idle := c.Idle(); idle != 0 {
+		t.Errorf("idle %v by the stopped clock", idle)
+	}
+}
+
+func TestClockShared(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewClock(start)
+	store := sse.NewMemoryStore(10)
+	peers := sse.NewPeerCounts(time.Minute)
+	s := sse.New(sse.WithClock(clock), sse.WithEventStore(store), sse.WithPeerCounts(peers))
+	s.Send(sse.Event{Data: "old", QoS: sse.QoSStore})
+	peers.Set("node", 5)
+	if n := s.Stats().Cluster; n != 5 {
+		t.Errorf("cluster %d clients", n)
+	}
+
+	clock.Advance(2 * time.Minute)
+	s.Send(sse.Event{Data: "new", QoS: sse.QoSStore})
+	events, err := store.After(start.Add(time.Minute))
+	if err != nil || len(events) != 1 || events[0].Data != "new" {
+		t.Errorf("events after a minute %+v, %v", events, err)
+	}
+	if n := s.Stats().Cluster; n != 0 {
+		t.Errorf("stale peer count %d", n)
+	}
+}
@@ -0,0 +1,27 @@
+// Package ssetest provides utilities for testing of HTML5 Server-Sent Events
+// servers and handlers.
+//
+// The Recorder connects to the handler and records the received events, so
+// the test reads as the specification of the stream:
+//
+//	func TestNotifications(t *testing.T) {
+//		broker := sse.New()
+//		defer broker.Close()
+//
+//		stream := ssetest.Connect(t, broker)
+//		go broker.Event("1", "notification", map[string]string{"text": "hello"})
+//
+//		e := stream.ExpectEvent(t, "notification", time.Second)
+//		if e.Data != `{"text":"hello"}` {
+//			t.Errorf("unexpected data %s", e.Data)
+//		}
+//	}
+//
+// The Clock replaces the time of the server and the client, so the recurring
+// events, the TTL and the reconnection delays are tested without the sleeps:
+//
+//	clock := ssetest.NewClock(time.Now())
+//	broker := sse.New(sse.WithClock(clock))
+//	broker.Every(time.Minute, tick)
+//	clock.Advance(time.Minute)
+package ssetest
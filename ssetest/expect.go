@@ -0,0 +1,100 @@
+package ssetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mdigger/sse"
+)
+
+// Expectation describes the next expected event of the stream. It is built
+// with the chained calls and checked with Within, so the test reads as the
+// specification of the stream:
+//
+//	var order Order
+//	stream.Expect().Event("order").WithJSON(&order).Within(time.Second)
+//
+// As with ExpectEvent, the events not matching the expectation are skipped.
+type Expectation struct {
+	r       *Recorder
+	match   []func(sse.Event) bool
+	desc    []string    // description of the expected event
+	decoded interface{} // decoded data of the event
+}
+
+// Expect returns the expectation of the next event after the previously
+// expected one. The failures are reported to the test of Connect.
+func (r *Recorder) Expect() *Expectation {
+	return &Expectation{r: r}
+}
+
+// Event expects the event with the name. The events without the name have the
+// "message" name.
+func (x *Expectation) Event(name string) *Expectation {
+	return x.where(fmt.Sprintf("named %q", name), func(e sse.Event) bool {
+		return e.Name == name || (e.Name == "" && name == "message")
+	})
+}
+
+// WithID expects the event with the identifier.
+func (x *Expectation) WithID(id string) *Expectation {
+	return x.where(fmt.Sprintf("with id %q", id), func(e sse.Event) bool {
+		return e.ID == id
+	})
+}
+
+// WithData expects the event with the data.
+func (x *Expectation) WithData(data string) *Expectation {
+	return x.where(fmt.Sprintf("with data %q", data), func(e sse.Event) bool {
+		return e.Data == data
+	})
+}
+
+// Where expects the event accepted by the function.
+func (x *Expectation) Where(fn func(sse.Event) bool) *Expectation {
+	return x.where("matching the function", fn)
+}
+
+// WithJSON decodes the JSON data of the expected event into v. The test fails
+// if the data is not a valid JSON of v.
+func (x *Expectation) WithJSON(v interface{}) *Expectation {
+	x.decoded = v
+	return x
+}
+
+// where adds the described condition of the expected event.
+func (x *Expectation) where(desc string, fn func(sse.Event) bool) *Expectation {
+	x.desc = append(x.desc, desc)
+	x.match = append(x.match, fn)
+	return x
+}
+
+// Within waits for the expected event and returns it. The test fails if the
+// event is not received within the given time.
+func (x *Expectation) Within(d time.Duration) sse.Event {
+	t := x.r.t
+	t.Helper()
+	e, ok := x.r.wait(d, func(e sse.Event) bool {
+		for _, match := range x.match {
+			if !match(e) {
+				return false
+			}
+		}
+		return true
+	})
+	if !ok {
+		var desc strings.Builder
+		for _, cond := range x.desc {
+			desc.WriteString(" " + cond)
+		}
+		t.Fatalf("ssetest: event%s is not received within %v", desc.String(), d)
+	}
+	if x.decoded != nil {
+		if err := json.Unmarshal([]byte(e.Data), x.decoded); err != nil {
+			t.Fatalf("ssetest: event %q data: %v", e.Name, err)
+		}
+	}
+	return e
+}
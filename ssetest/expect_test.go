@@ -0,0 +1,72 @@
+package ssetest
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdigger/sse"
+)
+
+func TestExpect(t *testing.T) {
+	s := sse.New()
+	defer s.Close()
+	stream := Connect(t, s)
+
+	go func() {
+		s.Send(sse.Event{Name: "order", ID: "1", Data: `{"id":1}`})
+		s.Send(sse.Event{Name: "order", ID: "2", Data: `{"id":2,"total":5}`})
+		s.Send(sse.Event{Data: "done"})
+	}()
+
+	var order struct{ ID, Total int }
+	e := stream.Expect().Event("order").WithID("2").WithJSON(&order).Within(time.Second)
+	if e.ID != "2" || order.ID != 2 || order.Total != 5 {
+		t.Errorf("event %+v decoded as %+v", e, order)
+	}
+	stream.Expect().Event("message").WithData("done").Within(time.Second)
+}
+
+// fatalTB records the test failure.
+type fatalTB struct {
+	testing.TB
+	failure string
+}
+
+func (t *fatalTB) Helper() {}
+
+func (t *fatalTB) Fatalf(format string, args ...interface{}) {
+	t.failure = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+func TestExpectFailure(t *testing.T) {
+	s := sse.New()
+	defer s.Close()
+	stream := Connect(t, s)
+	go s.Send(sse.Event{Name: "order", ID: "1", Data: "not json"})
+
+	for _, test := range []struct {
+		expect func(x *Expectation)
+		want   string
+	}{
+		{func(x *Expectation) { x.Event("order").WithID("2").Within(50 * time.Millisecond) },
+			`event named "order" with id "2" is not received within 50ms`},
+		{func(x *Expectation) { x.WithJSON(new(int)).Within(time.Second) },
+			`event "order" data: invalid character`},
+	} {
+		tb := &fatalTB{TB: t}
+		stream.t, stream.next = tb, 0
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			test.expect(stream.Expect())
+		}()
+		<-done
+		if !strings.Contains(tb.failure, test.want) {
+			t.Errorf("failure %q, want %q", tb.failure, test.want)
+		}
+	}
+}
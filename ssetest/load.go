@@ -0,0 +1,208 @@
+package ssetest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mdigger/sse"
+)
+
+// Load describes the load test: the number of concurrent clients connected to
+// the events stream and the events published while they are connected.
+type Load struct {
+	URL      string        // events stream URL
+	Handler  http.Handler  // events stream handler, served when URL is empty
+	Header   http.Header   // additional request headers
+	Clients  int           // number of concurrent clients
+	Events   int           // number of events to publish
+	Interval time.Duration // interval between published events
+	Wait     time.Duration // time to wait for the events, 1s by default
+
+	// Publish is called to publish the event with the given identifier. The
+	// event must have exactly this identifier, so the clients can measure its
+	// latency.
+	Publish func(id string) error
+}
+
+// LoadStats is the result of the load test.
+type LoadStats struct {
+	Connected  int           // number of connected clients
+	Failed     int           // number of failed connections
+	Published  int           // number of published events
+	Received   int           // number of events received by all clients
+	Dropped    int           // number of published events not received
+	Duration   time.Duration // time from the first event to the last one
+	Throughput float64       // received events per second
+	Latency    Latency       // delivery latency of the received events
+}
+
+// Latency describes the distribution of the delivery latency.
+type Latency struct {
+	Min, Mean, P50, P99, Max time.Duration
+}
+
+// Run connects the clients, publishes the events and waits for them to be
+// received, then reports the statistics.
+func (l *Load) Run(ctx context.Context) (*LoadStats, error) {
+	if l.Publish == nil {
+		return nil, errors.New("ssetest: publish function is not set")
+	}
+	url := l.URL
+	client := http.DefaultClient
+	if url == "" {
+		if l.Handler == nil {
+			return nil, errors.New("ssetest: neither URL nor handler is set")
+		}
+		ts := httptest.NewServer(l.Handler)
+		defer ts.Close()
+		url, client = ts.URL, ts.Client()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		sent      = make([]int64, l.Events) // publication time of the events
+		mu        sync.Mutex
+		latencies []time.Duration
+		stats     LoadStats
+		wg        sync.WaitGroup
+		ready     sync.WaitGroup
+		received  int64
+		last      int64 // time of the last received event
+	)
+	ready.Add(l.Clients)
+	for i := 0; i < l.Clients; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := l.connect(ctx, client, url)
+			mu.Lock()
+			if err != nil {
+				stats.Failed++
+			} else {
+				stats.Connected++
+			}
+			mu.Unlock()
+			ready.Done()
+			if err != nil {
+				return
+			}
+			defer res.Body.Close()
+
+			var local []time.Duration
+			d := sse.NewDecoder(res.Body)
+			for {
+				e, err := d.Decode()
+				if err != nil {
+					break
+				}
+				now := time.Now().UnixNano()
+				seq, err := strconv.Atoi(e.ID)
+				if err != nil || seq < 1 || seq > len(sent) {
+					continue // not the test event
+				}
+				if at := atomic.LoadInt64(&sent[seq-1]); at > 0 {
+					local = append(local, time.Duration(now-at))
+				}
+				atomic.StoreInt64(&last, now)
+				if atomic.AddInt64(&received, 1) == int64(l.Clients*l.Events) {
+					cancel() // all events are received
+				}
+			}
+			mu.Lock()
+			latencies = append(latencies, local...)
+			mu.Unlock()
+		}()
+	}
+	ready.Wait()
+
+	start := time.Now()
+	for i := 1; i <= l.Events; i++ {
+		if i > 1 && l.Interval > 0 {
+			select {
+			case <-time.After(l.Interval):
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		atomic.StoreInt64(&sent[i-1], time.Now().UnixNano())
+		if err := l.Publish(strconv.Itoa(i)); err != nil {
+			cancel()
+			wg.Wait()
+			return nil, err
+		}
+		stats.Published++
+	}
+
+	wait := l.Wait
+	if wait <= 0 {
+		wait = time.Second
+	}
+	select {
+	case <-time.After(wait):
+		cancel()
+	case <-ctx.Done():
+	}
+	wg.Wait()
+
+	stats.Received = int(atomic.LoadInt64(&received))
+	stats.Dropped = stats.Connected*stats.Published - stats.Received
+	if last := atomic.LoadInt64(&last); last > 0 {
+		stats.Duration = time.Duration(last - start.UnixNano())
+	}
+	if stats.Duration > 0 {
+		stats.Throughput = float64(stats.Received) / stats.Duration.Seconds()
+	}
+	stats.Latency = distribution(latencies)
+	return &stats, nil
+}
+
+// connect opens the events stream.
+func (l *Load) connect(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range l.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, errors.New("ssetest: unexpected status " + res.Status)
+	}
+	return res, nil
+}
+
+// distribution returns the latency distribution of the values.
+func distribution(values []time.Duration) Latency {
+	if len(values) == 0 {
+		return Latency{}
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	var sum time.Duration
+	for _, v := range values {
+		sum += v
+	}
+	return Latency{
+		Min:  values[0],
+		Mean: sum / time.Duration(len(values)),
+		P50:  values[len(values)*50/100],
+		P99:  values[len(values)*99/100],
+		Max:  values[len(values)-1],
+	}
+}
@@ -0,0 +1,61 @@
+package ssetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mdigger/sse"
+)
+
+func TestLoad(t *testing.T) {
+	s := sse.New()
+	defer s.Close()
+	load := &Load{
+		Handler: s,
+		Clients: 20,
+		Events:  50,
+		Wait:    5 * time.Second,
+		Publish: func(id string) error {
+			s.Send(sse.Event{ID: id, Data: "payload"})
+			return nil
+		},
+	}
+	stats, err := load.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Connected != 20 || stats.Failed != 0 {
+		t.Errorf("connected %d, failed %d", stats.Connected, stats.Failed)
+	}
+	if stats.Published != 50 || stats.Received != 1000 || stats.Dropped != 0 {
+		t.Errorf("published %d, received %d, dropped %d", stats.Published, stats.Received, stats.Dropped)
+	}
+	if stats.Latency.Max <= 0 || stats.Latency.Min > stats.Latency.Max || stats.Throughput <= 0 {
+		t.Errorf("invalid statistics %+v", stats)
+	}
+}
+
+func TestLoadDropped(t *testing.T) {
+	s := sse.New()
+	defer s.Close()
+	load := &Load{
+		Handler: s.Handler(sse.WithFilter(func(e sse.Event, _ sse.ClientInfo) bool {
+			return e.ID != "2" // emulate the lost event
+		})),
+		Clients: 2,
+		Events:  3,
+		Wait:    100 * time.Millisecond,
+		Publish: func(id string) error {
+			s.Send(sse.Event{ID: id, Data: "payload"})
+			return nil
+		},
+	}
+	stats, err := load.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Received != 4 || stats.Dropped != 2 {
+		t.Errorf("received %d, dropped %d", stats.Received, stats.Dropped)
+	}
+}
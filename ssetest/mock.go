@@ -0,0 +1,92 @@
+package ssetest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mdigger/sse"
+)
+
+// Mock is the sse.Publisher recording everything published. It is safe for
+// concurrent use.
+type Mock struct {
+	mu       sync.Mutex
+	events   []sse.Event
+	comments []string
+	retries  []time.Duration
+	closed   bool
+}
+
+var _ sse.Publisher = (*Mock)(nil)
+
+// Send records the event.
+func (m *Mock) Send(e sse.Event) {
+	m.mu.Lock()
+	m.events = append(m.events, e)
+	m.mu.Unlock()
+}
+
+// Publish records the event with the given data and options.
+func (m *Mock) Publish(data string, opts ...sse.EventOption) error {
+	m.Send(sse.NewEventData(data, opts...))
+	return nil
+}
+
+// Event records the event with the data encoded as the sse.Server does it.
+func (m *Mock) Event(id, name string, v interface{}) error {
+	e, err := sse.NewEvent(id, name, v)
+	if err != nil {
+		return err
+	}
+	m.Send(e)
+	return nil
+}
+
+// Comment records the comment.
+func (m *Mock) Comment(text string) {
+	m.mu.Lock()
+	m.comments = append(m.comments, text)
+	m.mu.Unlock()
+}
+
+// Retry records the reconnection delay.
+func (m *Mock) Retry(d time.Duration) {
+	m.mu.Lock()
+	m.retries = append(m.retries, d)
+	m.mu.Unlock()
+}
+
+// Close records that the publisher is closed.
+func (m *Mock) Close() {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+}
+
+// Events returns the recorded events.
+func (m *Mock) Events() []sse.Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]sse.Event(nil), m.events...)
+}
+
+// Comments returns the recorded comments.
+func (m *Mock) Comments() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.comments...)
+}
+
+// Retries returns the recorded reconnection delays.
+func (m *Mock) Retries() []time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]time.Duration(nil), m.retries...)
+}
+
+// Closed reports whether the publisher is closed.
+func (m *Mock) Closed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.closed
+}
@@ -0,0 +1,43 @@
+package ssetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mdigger/sse"
+)
+
+// notify is the application code under the test.
+func notify(p sse.Publisher, user string) error {
+	p.Comment("notify " + user)
+	return p.Event("1", "notification", map[string]string{"user": user})
+}
+
+func TestMock(t *testing.T) {
+	m := new(Mock)
+	if err := notify(m, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	m.Retry(time.Second)
+	m.Close()
+
+	events := m.Events()
+	if len(events) != 1 {
+		t.Fatalf("recorded %d events", len(events))
+	}
+	if want := (sse.Event{ID: "1", Name: "notification", Data: `{"user":"alice"}`}); events[0] != want {
+		t.Errorf("event %+v, want %+v", events[0], want)
+	}
+	if c := m.Comments(); len(c) != 1 || c[0] != "notify alice" {
+		t.Errorf("comments %q", c)
+	}
+	if r := m.Retries(); len(r) != 1 || r[0] != time.Second {
+		t.Errorf("retries %v", r)
+	}
+	if !m.Closed() {
+		t.Error("not closed")
+	}
+	if err := m.Event("", "", func() {}); err == nil {
+		t.Error("not encodable data accepted")
+	}
+}
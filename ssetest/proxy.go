@@ -0,0 +1,250 @@
+package ssetest
+
+import (
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Proxy is the TCP proxy between the events server and the test clients
+// injecting the network faults: the latency, the stalled reads, the frames
+// truncated mid-event and the abruptly reset connections. It exercises the
+// slow client, the deadline and the reconnection paths:
+//
+//	ts := httptest.NewServer(broker)
+//	defer ts.Close()
+//	proxy := ssetest.NewProxy(t, ts.URL)
+//	stream := ssetest.ConnectURL(t, proxy.URL)
+//	proxy.Stall() // the broker writes are not read anymore
+type Proxy struct {
+	URL string // URL of the proxy, use it instead of the server URL
+
+	ln      net.Listener
+	target  string // host:port of the server
+	mu      sync.Mutex
+	conns   map[*proxyConn]struct{}
+	latency time.Duration // delay of the data sent to the clients
+	stall   chan struct{} // closed when the stalled reads are resumed
+	cut     bool          // truncate the next data sent to a client
+	closed  chan struct{}
+}
+
+// NewProxy starts the proxy of the server at the target URL. The proxy is
+// closed when the test ends.
+func NewProxy(t testing.TB, target string) *Proxy {
+	t.Helper()
+	u, err := url.Parse(target)
+	if err != nil {
+		t.Fatal("ssetest: proxy target:", err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("ssetest: proxy listen:", err)
+	}
+	proxied := *u
+	proxied.Host = ln.Addr().String()
+	p := &Proxy{
+		URL:    proxied.String(),
+		ln:     ln,
+		target: u.Host,
+		conns:  make(map[*proxyConn]struct{}),
+		closed: make(chan struct{}),
+	}
+	t.Cleanup(p.Close)
+	go p.accept()
+	return p
+}
+
+// SetLatency delays every data sent from the server to the clients. Zero
+// removes the latency.
+func (p *Proxy) SetLatency(d time.Duration) {
+	p.mu.Lock()
+	p.latency = d
+	p.mu.Unlock()
+}
+
+// Stall holds the data of the server until Unstall and stops reading more, so
+// its writes to the clients are blocked when the network buffers are full.
+func (p *Proxy) Stall() {
+	p.mu.Lock()
+	if p.stall == nil {
+		p.stall = make(chan struct{})
+	}
+	p.mu.Unlock()
+}
+
+// Unstall resumes reading the data of the server.
+func (p *Proxy) Unstall() {
+	p.mu.Lock()
+	if p.stall != nil {
+		close(p.stall)
+		p.stall = nil
+	}
+	p.mu.Unlock()
+}
+
+// Truncate cuts the next data sent from the server to a client in half and
+// closes the connection, so the client receives the partial frame.
+func (p *Proxy) Truncate() {
+	p.mu.Lock()
+	p.cut = true
+	p.mu.Unlock()
+}
+
+// Reset abruptly resets all the proxied connections, both to the clients and
+// to the server, without the graceful close.
+func (p *Proxy) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for pc := range p.conns {
+		pc.reset()
+	}
+}
+
+// Conns returns the number of the proxied connections.
+func (p *Proxy) Conns() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.conns)
+}
+
+// Close stops the proxy and closes all the proxied connections.
+func (p *Proxy) Close() {
+	p.mu.Lock()
+	select {
+	case <-p.closed:
+		p.mu.Unlock()
+		return
+	default:
+	}
+	close(p.closed)
+	p.ln.Close()
+	for pc := range p.conns {
+		pc.close()
+	}
+	p.mu.Unlock()
+}
+
+// accept proxies the accepted connections until the proxy is closed.
+func (p *Proxy) accept() {
+	for {
+		client, err := p.ln.Accept()
+		if err != nil {
+			return
+		}
+		go p.serve(client)
+	}
+}
+
+// serve proxies the client connection to the server.
+func (p *Proxy) serve(client net.Conn) {
+	server, err := net.Dial("tcp", p.target)
+	if err != nil {
+		client.Close()
+		return
+	}
+	pc := &proxyConn{client: client, server: server, done: make(chan struct{})}
+	p.mu.Lock()
+	select {
+	case <-p.closed:
+		p.mu.Unlock()
+		pc.close()
+		return
+	default:
+	}
+	p.conns[pc] = struct{}{}
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.conns, pc)
+		p.mu.Unlock()
+		pc.close()
+	}()
+
+	go func() {
+		_, _ = io.Copy(server, client)
+		pc.close()
+	}()
+	p.forward(pc)
+}
+
+// forward sends the data of the server to the client with the injected
+// faults until the connection is closed.
+func (p *Proxy) forward(pc *proxyConn) {
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := pc.server.Read(buf)
+		if n > 0 {
+			if !p.unstalled(pc) {
+				return
+			}
+			p.mu.Lock()
+			latency, cut := p.latency, p.cut
+			p.cut = false
+			p.mu.Unlock()
+			if latency > 0 {
+				select {
+				case <-time.After(latency):
+				case <-pc.done:
+					return
+				}
+			}
+			data := buf[:n]
+			if cut {
+				data = data[:n/2]
+			}
+			if _, err := pc.client.Write(data); err != nil || cut {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// unstalled waits until the reads of the server are not stalled. It returns
+// false if the connection is closed before.
+func (p *Proxy) unstalled(pc *proxyConn) bool {
+	p.mu.Lock()
+	stall := p.stall
+	p.mu.Unlock()
+	if stall == nil {
+		return true
+	}
+	select {
+	case <-stall:
+		return true
+	case <-pc.done:
+		return false
+	}
+}
+
+// proxyConn is the pair of the proxied connections.
+type proxyConn struct {
+	client, server net.Conn
+	once           sync.Once
+	done           chan struct{} // closed when the connections are closed
+}
+
+// close closes both connections.
+func (pc *proxyConn) close() {
+	pc.once.Do(func() {
+		close(pc.done)
+		pc.client.Close()
+		pc.server.Close()
+	})
+}
+
+// reset closes both connections with the TCP reset.
+func (pc *proxyConn) reset() {
+	for _, conn := range []net.Conn{pc.client, pc.server} {
+		if tcp, ok := conn.(*net.TCPConn); ok {
+			_ = tcp.SetLinger(0)
+		}
+	}
+	pc.close()
+}
@@ -0,0 +1,99 @@
+package ssetest
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdigger/sse"
+)
+
+// proxied returns the recorder connected to the server through the proxy.
+func proxied(t *testing.T, s *sse.Server) (*Proxy, *Recorder) {
+	t.Helper()
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	proxy := NewProxy(t, ts.URL)
+	stream := ConnectURL(t, proxy.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.WaitForClients(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	return proxy, stream
+}
+
+func TestProxyLatency(t *testing.T) {
+	s := sse.New()
+	defer s.Close()
+	proxy, stream := proxied(t, s)
+	if n := proxy.Conns(); n != 1 {
+		t.Errorf("%d proxied connections", n)
+	}
+
+	proxy.SetLatency(100 * time.Millisecond)
+	start := time.Now()
+	s.Send(sse.Event{Data: "slow"})
+	stream.ExpectEvent(t, "message", time.Second)
+	if d := time.Since(start); d < 100*time.Millisecond {
+		t.Errorf("received in %v", d)
+	}
+}
+
+func TestProxyStall(t *testing.T) {
+	s := sse.New(sse.WithBufferSize(1), sse.WithSlowClientPolicy(sse.SlowClientDisconnect))
+	defer s.Close()
+	proxy, stream := proxied(t, s)
+
+	proxy.Stall()
+	s.Send(sse.Event{Data: "held"})
+	stream.ExpectNoEvent(t, 50*time.Millisecond)
+	proxy.Unstall()
+	stream.ExpectEvent(t, "message", time.Second)
+
+	proxy.Stall()
+	data := strings.Repeat("x", 64<<10)
+	deadline := time.Now().Add(10 * time.Second)
+	for s.Connected() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("slow client is not disconnected")
+		}
+		s.Send(sse.Event{Data: data})
+	}
+}
+
+func TestProxyTruncate(t *testing.T) {
+	s := sse.New()
+	defer s.Close()
+	proxy, stream := proxied(t, s)
+
+	proxy.Truncate()
+	s.Send(sse.Event{Name: "cut", Data: strings.Repeat("x", 1000)})
+	if e, ok := stream.Next(time.Second); ok {
+		t.Errorf("truncated event %q received", e.Name)
+	}
+	if stream.Err() == nil {
+		t.Error("no error of the truncated stream")
+	}
+}
+
+func TestProxyReset(t *testing.T) {
+	s := sse.New()
+	defer s.Close()
+	proxy, stream := proxied(t, s)
+
+	proxy.Reset()
+	if _, ok := stream.Next(time.Second); ok {
+		t.Error("event after the reset")
+	}
+	if stream.Err() == nil {
+		t.Error("no error of the reset stream")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.WaitForClients(ctx, 0); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,186 @@
+package ssetest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mdigger/sse"
+)
+
+// Option modifies the stream request of the Recorder.
+type Option func(*http.Request)
+
+// WithHeader adds the header to the stream request.
+func WithHeader(key, value string) Option {
+	return func(r *http.Request) {
+		r.Header.Add(key, value)
+	}
+}
+
+// WithQuery adds the query parameter to the stream request.
+func WithQuery(key, value string) Option {
+	return func(r *http.Request) {
+		q := r.URL.Query()
+		q.Add(key, value)
+		r.URL.RawQuery = q.Encode()
+	}
+}
+
+// Recorder is the in-memory client of the events stream which records the
+// received events.
+type Recorder struct {
+	mu      sync.Mutex
+	events  []sse.Event   // received events
+	updated chan struct{} // closed when the events are changed
+	err     error         // stream read error
+	done    bool          // the stream is ended
+	next    int           // position of the next expected event
+	cancel  context.CancelFunc
+	t       testing.TB // test of the expectations
+}
+
+// Connect serves the handler with the test HTTP server and connects the
+// recorder to its events stream. It returns when the response headers are
+// received. The connection and the server are closed when the test ends.
+func Connect(t testing.TB, h http.Handler, opts ...Option) *Recorder {
+	t.Helper()
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+	return connect(t, ts.URL, ts.Client(), opts)
+}
+
+// ConnectURL connects the recorder to the events stream at the URL, such as
+// the URL of the Proxy. It returns when the response headers are received.
+// The connection is closed when the test ends.
+func ConnectURL(t testing.TB, url string, opts ...Option) *Recorder {
+	t.Helper()
+	return connect(t, url, http.DefaultClient, opts)
+}
+
+// connect connects the recorder to the events stream at the URL.
+func connect(t testing.TB, url string, client *http.Client, opts []Option) *Recorder {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Recorder{updated: make(chan struct{}), cancel: cancel, t: t}
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	for _, opt := range opts {
+		opt(req)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal("ssetest: connect:", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		t.Fatal("ssetest: unexpected status:", res.Status)
+	}
+
+	go func() {
+		defer res.Body.Close()
+		d := sse.NewDecoder(res.Body)
+		for {
+			e, err := d.Decode()
+			r.mu.Lock()
+			if err != nil {
+				r.err, r.done = err, true
+			} else {
+				r.events = append(r.events, e)
+			}
+			close(r.updated)
+			r.updated = make(chan struct{})
+			r.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return r
+}
+
+// Close closes the connection to the stream.
+func (r *Recorder) Close() {
+	r.cancel()
+}
+
+// Events returns all events received so far.
+func (r *Recorder) Events() []sse.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]sse.Event(nil), r.events...)
+}
+
+// Err returns the error the stream is ended with or nil.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Next waits for the next event after the previously returned or expected one
+// and returns it. It returns false if the event is not received within the
+// given time or the stream is ended.
+func (r *Recorder) Next(within time.Duration) (sse.Event, bool) {
+	return r.wait(within, func(sse.Event) bool { return true })
+}
+
+// ExpectEvent waits for the event with the given name after the previously
+// expected one, skipping other events, and returns it. The test fails if the
+// event is not received within the given time. The events without the name
+// have the "message" name.
+func (r *Recorder) ExpectEvent(t testing.TB, name string, within time.Duration) sse.Event {
+	t.Helper()
+	e, ok := r.wait(within, func(e sse.Event) bool {
+		return e.Name == name || (e.Name == "" && name == "message")
+	})
+	if !ok {
+		t.Fatalf("ssetest: event %q is not received within %v", name, within)
+	}
+	return e
+}
+
+// ExpectNoEvent fails the test if any event is received within the given time
+// after the previously expected one.
+func (r *Recorder) ExpectNoEvent(t testing.TB, within time.Duration) {
+	t.Helper()
+	if e, ok := r.Next(within); ok {
+		t.Fatalf("ssetest: unexpected event %+v", e)
+	}
+}
+
+// wait waits for the event accepted by the match function.
+func (r *Recorder) wait(within time.Duration, match func(sse.Event) bool) (sse.Event, bool) {
+	timer := time.NewTimer(within)
+	defer timer.Stop()
+	for {
+		r.mu.Lock()
+		for r.next < len(r.events) {
+			e := r.events[r.next]
+			r.next++
+			if match(e) {
+				r.mu.Unlock()
+				return e, true
+			}
+		}
+		updated, done := r.updated, r.done
+		r.mu.Unlock()
+		if done {
+			return sse.Event{}, false
+		}
+
+		select {
+		case <-updated:
+		case <-timer.C:
+			return sse.Event{}, false
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package ssetest
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mdigger/sse"
+)
+
+func TestRecorder(t *testing.T) {
+	s := sse.New(sse.WithChannels(func(r *http.Request) []string {
+		return r.URL.Query()["channel"]
+	}))
+	defer s.Close()
+	r := Connect(t, s, WithQuery("channel", "orders"))
+
+	go func() {
+		s.Send(sse.Event{Name: "ping"})
+		s.Send(sse.Event{Name: "ping", Data: "1"})
+		s.Send(sse.Event{Name: "order", Data: "42", Channel: "orders"})
+		s.Send(sse.Event{Name: "order", Data: "13", Channel: "other"})
+		s.Send(sse.Event{Data: "done"})
+	}()
+
+	if e := r.ExpectEvent(t, "order", time.Second); e.Data != "42" {
+		t.Errorf("order %q, want 42", e.Data)
+	}
+	if e := r.ExpectEvent(t, "message", time.Second); e.Data != "done" {
+		t.Errorf("message %q, want done", e.Data)
+	}
+	r.ExpectNoEvent(t, 50*time.Millisecond)
+	if n := len(r.Events()); n != 3 {
+		t.Errorf("recorded %d events, want 3", n)
+	}
+
+	s.Close()
+	if _, ok := r.Next(time.Second); ok {
+		t.Error("event after the stream end")
+	}
+	if r.Err() == nil {
+		t.Error("no error after the stream end")
+	}
+}
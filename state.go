@@ -0,0 +1,77 @@
+package sse
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// StateStream implements the snapshot-then-delta pattern of live dashboards:
+// each new connection first receives the full snapshot of the state followed
+// by the delta events in order. The snapshot is taken atomically with the
+// client registration, so no delta published in between is lost or
+// duplicated.
+//
+// The events are sequenced: the deltas without the identifier get the next
+// sequence number, and the snapshot without the identifier gets the number of
+// the last delta it includes.
+type StateStream struct {
+	server   *Server
+	snapshot func() (Event, error)
+	seq      uint64 // last delta sequence number
+	mu       sync.Mutex
+}
+
+// NewStateStream returns the state stream published by the server. The
+// snapshot function returns the full current state; the deltas published with
+// Delta must be applied to it when it is called. The server should not be
+// used to publish other events.
+func NewStateStream(s *Server, snapshot func() (Event, error)) *StateStream {
+	return &StateStream{server: s, snapshot: snapshot}
+}
+
+// Delta sends the delta event to all connected clients. The state change must
+// be applied before, so the next snapshot includes it.
+func (st *StateStream) Delta(e Event) {
+	st.Update(func() (Event, bool) { return e, true })
+}
+
+// Update calls the function to change the state and sends the returned delta
+// event to all connected clients atomically, so no snapshot is taken in
+// between. The delta is not sent if the function returns false.
+func (st *StateStream) Update(fn func() (Event, bool)) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	e, ok := fn()
+	if !ok {
+		return
+	}
+	st.seq++
+	if e.ID == "" {
+		e.ID = strconv.FormatUint(st.seq, 10)
+	}
+	st.server.Send(e)
+}
+
+// ServeHTTP implements http.Handler interface.
+func (st *StateStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	st.server.serve(w, r, &handler{server: st.server, join: st.join})
+}
+
+// join registers the client with the snapshot as the initial message.
+func (st *StateStream) join(c *client, register func(*client)) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	e, err := st.snapshot()
+	if err != nil {
+		return err
+	}
+	if e.ID == "" {
+		e.ID = strconv.FormatUint(st.seq, 10)
+	}
+	if data, ok := st.server.render(c, e, ""); ok {
+		c.initial = append(c.initial, data)
+	}
+	register(c)
+	return nil
+}
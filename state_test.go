@@ -0,0 +1,66 @@
+package sse
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestStateStream(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		counter int
+	)
+	s := New()
+	st := NewStateStream(s, func() (Event, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return Event{Name: "snapshot", Data: strconv.Itoa(counter)}, nil
+	})
+	ts := httptest.NewServer(st)
+	defer ts.Close()
+	defer s.Close()
+
+	increment := func() {
+		mu.Lock()
+		counter++
+		mu.Unlock()
+		st.Delta(Event{Name: "delta", Data: "+1"})
+	}
+	increment()
+
+	r := connect(t, ts, nil)
+	if msg := readMessage(t, r); msg != "event: snapshot\ndata: 1\nid: 1\n" {
+		t.Errorf("snapshot %q", msg)
+	}
+	go increment()
+	if msg := readMessage(t, r); msg != "event: delta\ndata: +1\nid: 2\n" {
+		t.Errorf("delta %q", msg)
+	}
+}
+
+func TestStateStreamError(t *testing.T) {
+	s := New()
+	st := NewStateStream(s, func() (Event, error) {
+		return Event{}, errors.New("no state")
+	})
+	ts := httptest.NewServer(st)
+	defer ts.Close()
+
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Errorf("status %s", res.Status)
+	}
+	if n := s.Connected(); n != 0 {
+		t.Errorf("%d clients connected", n)
+	}
+}
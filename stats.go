@@ -0,0 +1,34 @@
+package sse
+
+// Stats is the snapshot of the server state.
+type Stats struct {
+	Clients   int            // number of connected clients
+	Cluster   int            // number of clients of all instances (WithPeerCounts)
+	Protocols map[string]int // number of clients by the connection protocol
+	Expired   uint64         // number of events expired in the client queues
+	Sent      uint64         // number of events sent
+	Delivered uint64         // number of messages queued to the clients
+	Dropped   uint64         // number of messages dropped for the slow clients
+}
+
+// Stats returns the current statistics of the server.
+func (s *Server) Stats() Stats {
+	s.mu.RLock()
+	stats := Stats{
+		Clients:   len(s.clients),
+		Protocols: make(map[string]int),
+	}
+	for c := range s.clients {
+		stats.Protocols[c.info.Proto]++
+	}
+	s.mu.RUnlock()
+	stats.Expired = s.expired.Load()
+	stats.Sent = s.sent.Load()
+	stats.Delivered = s.delivered.Load()
+	stats.Dropped = s.dropped.Load()
+	stats.Cluster = stats.Clients
+	if s.peers != nil {
+		stats.Cluster += s.peers.Total()
+	}
+	return stats
+}
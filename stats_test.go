@@ -0,0 +1,45 @@
+package sse
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	s := New()
+	h1 := httptest.NewServer(s)
+	defer h1.Close()
+	h2 := httptest.NewUnstartedServer(s.Handler(WithHeader("Connection", "keep-alive")))
+	h2.EnableHTTP2 = true
+	h2.StartTLS()
+	defer h2.Close()
+	defer s.Close()
+
+	connect(t, h1, nil)
+	req, err := http.NewRequest("GET", h2.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := h2.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.ProtoMajor != 2 {
+		t.Fatal("protocol", res.Proto)
+	}
+	waitConnected(t, s, 2)
+
+	stats := s.Stats()
+	if stats.Clients != 2 || stats.Protocols["HTTP/1.1"] != 1 || stats.Protocols["HTTP/2.0"] != 1 {
+		t.Errorf("stats %+v", stats)
+	}
+
+	go s.Send(Event{Data: "h2"})
+	if msg := readMessage(t, bufio.NewReader(res.Body)); msg != "data: h2\n" {
+		t.Errorf("message %q", msg)
+	}
+}
@@ -0,0 +1,100 @@
+package sse
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxStatsDPacket is the maximum size of the StatsD packet fitting the
+// Ethernet MTU.
+const maxStatsDPacket = 1432
+
+// StatsD is the MetricsSink sending the metrics to the StatsD server over
+// UDP. The tags, if any, are sent in the Datadog format, such as "env:prod".
+// The metrics are buffered until Flush.
+type StatsD struct {
+	conn   net.Conn
+	prefix string // metric names prefix
+	tags   string // encoded tags suffix
+	mu     sync.Mutex
+	buf    []byte // metrics not sent yet
+	err    error  // first send error since the flush
+}
+
+var _ MetricsSink = (*StatsD)(nil)
+
+// NewStatsD returns the sink of the StatsD server at the address, such as
+// "127.0.0.1:8125". The prefix, if not empty, is added to the metric names with
+// the dot.
+func NewStatsD(addr, prefix string, tags ...string) (*StatsD, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	d := &StatsD{conn: conn}
+	if prefix != "" {
+		d.prefix = prefix + "."
+	}
+	if len(tags) > 0 {
+		d.tags = "|#" + strings.Join(tags, ",")
+	}
+	return d, nil
+}
+
+// Gauge implements MetricsSink interface.
+func (d *StatsD) Gauge(name string, value float64) {
+	d.add(name, strconv.FormatFloat(value, 'f', -1, 64), "g")
+}
+
+// Count implements MetricsSink interface.
+func (d *StatsD) Count(name string, delta int64) {
+	d.add(name, strconv.FormatInt(delta, 10), "c")
+}
+
+// add buffers the metric, sending the buffered ones first if the packet would
+// be too large.
+func (d *StatsD) add(name, value, kind string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	size := len(d.prefix) + len(name) + len(value) + len(kind) + len(d.tags) + 3
+	if len(d.buf) > 0 && len(d.buf)+size > maxStatsDPacket {
+		d.send()
+	}
+	d.buf = append(d.buf, d.prefix...)
+	d.buf = append(d.buf, name...)
+	d.buf = append(d.buf, ':')
+	d.buf = append(d.buf, value...)
+	d.buf = append(d.buf, '|')
+	d.buf = append(d.buf, kind...)
+	d.buf = append(d.buf, d.tags...)
+	d.buf = append(d.buf, '\n')
+}
+
+// send sends the buffered metrics as one packet. It must be called with the
+// lock held.
+func (d *StatsD) send() {
+	if _, err := d.conn.Write(d.buf[:len(d.buf)-1]); err != nil && d.err == nil {
+		d.err = err
+	}
+	d.buf = d.buf[:0]
+}
+
+// Flush implements MetricsSink interface. It returns the first send error
+// since the previous flush.
+func (d *StatsD) Flush() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.buf) > 0 {
+		d.send()
+	}
+	err := d.err
+	d.err = nil
+	return err
+}
+
+// Close closes the connection to the StatsD server.
+func (d *StatsD) Close() error {
+	return d.conn.Close()
+}
@@ -0,0 +1,65 @@
+package sse
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listenStatsD returns the UDP listener of the test StatsD server.
+func listenStatsD(t *testing.T) net.PacketConn {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readPacket returns the next packet received by the StatsD server.
+func readPacket(t *testing.T, conn net.PacketConn) string {
+	t.Helper()
+	buf := make([]byte, 2048)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf[:n])
+}
+
+func TestStatsD(t *testing.T) {
+	server := listenStatsD(t)
+	d, err := NewStatsD(server.LocalAddr().String(), "sse", "env:test", "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	d.Gauge("clients", 3)
+	d.Count("events.sent", 42)
+	if err := d.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := "sse.clients:3|g|#env:test,app\nsse.events.sent:42|c|#env:test,app"
+	if packet := readPacket(t, server); packet != want {
+		t.Errorf("packet %q, want %q", packet, want)
+	}
+
+	for i := 0; i < 100; i++ {
+		d.Count("events.dropped", 1)
+	}
+	if err := d.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	var lines int
+	for lines < 100 {
+		packet := readPacket(t, server)
+		if len(packet) > maxStatsDPacket {
+			t.Fatalf("packet of %d bytes", len(packet))
+		}
+		lines += strings.Count(packet, "\n") + 1
+	}
+}
@@ -0,0 +1,142 @@
+package sse
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// EventStore keeps the events for the replay to the reconnecting clients.
+type EventStore interface {
+	// Append stores the event and returns it with the identifier assigned by
+	// the store if empty.
+	Append(e Event) (Event, error)
+	// Since returns the stored events after the one with the identifier. If the
+	// event is not stored anymore, all stored events are returned.
+	Since(lastEventID string) ([]Event, error)
+}
+
+// TimeStore is the EventStore replaying the events by the time they are
+// stored, for the clients connecting with the since query parameter.
+type TimeStore interface {
+	EventStore
+	// After returns the events stored after the time.
+	After(t time.Time) ([]Event, error)
+}
+
+// WithEventStore sets the store of the events sent with the QoSStore and
+// QoSAck levels. The events are replayed to the clients reconnecting with the
+// Last-Event-ID header. If the store implements TimeStore, the clients
+// connecting without the Last-Event-ID get the events stored since the time
+// of the since query parameter: absolute (?since=2024-01-02T15:04:05Z) or
+// relative (?since=5m).
+func WithEventStore(store EventStore) Option {
+	return func(s *Server) {
+		s.store = store
+	}
+}
+
+// MemoryStore is the in-memory EventStore keeping the limited number of the
+// last events. The events without the identifier get the sequence number.
+type MemoryStore struct {
+	mu     sync.Mutex
+	events []storedEvent // ring buffer
+	start  int           // index of the oldest event
+	full   bool
+	seq    uint64
+	clock  Clock // time of the stored events, the system clock if nil
+}
+
+// storedEvent is the event stored in the memory.
+type storedEvent struct {
+	Event
+	stored time.Time
+}
+
+// NewMemoryStore returns the in-memory store of the given number of the last
+// events.
+func NewMemoryStore(size int) *MemoryStore {
+	if size < 1 {
+		size = 1
+	}
+	return &MemoryStore{events: make([]storedEvent, 0, size)}
+}
+
+// Append implements EventStore interface.
+func (m *MemoryStore) Append(e Event) (Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seq++
+	if e.ID == "" {
+		e.ID = strconv.FormatUint(m.seq, 10)
+	}
+	stored := storedEvent{Event: e, stored: clockOrSystem(m.clock).Now()}
+	if !m.full {
+		m.events = append(m.events, stored)
+		m.full = len(m.events) == cap(m.events)
+		return e, nil
+	}
+	m.events[m.start] = stored
+	m.start = (m.start + 1) % len(m.events)
+	return e, nil
+}
+
+// useClock sets the clock of the server storing the events.
+func (m *MemoryStore) useClock(clock Clock) {
+	m.mu.Lock()
+	m.clock = clock
+	m.mu.Unlock()
+}
+
+// Since implements EventStore interface.
+func (m *MemoryStore) Since(lastEventID string) ([]Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := m.ordered()
+	for i := len(stored) - 1; i >= 0; i-- {
+		if stored[i].ID == lastEventID {
+			return eventsOf(stored[i+1:]), nil
+		}
+	}
+	return eventsOf(stored), nil
+}
+
+// After implements TimeStore interface.
+func (m *MemoryStore) After(t time.Time) ([]Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := m.ordered()
+	for i, e := range stored {
+		if e.stored.After(t) {
+			return eventsOf(stored[i:]), nil
+		}
+	}
+	return []Event{}, nil
+}
+
+// ordered returns the stored events from the oldest one.
+func (m *MemoryStore) ordered() []storedEvent {
+	return append(m.events[m.start:len(m.events):len(m.events)], m.events[:m.start]...)
+}
+
+// eventsOf returns the events of the stored ones.
+func eventsOf(stored []storedEvent) []Event {
+	events := make([]Event, len(stored))
+	for i, e := range stored {
+		events[i] = e.Event
+	}
+	return events
+}
+
+// since returns the time of the since query parameter, absolute or relative
+// to now.
+func since(value string, now time.Time) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), true
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, err == nil
+}
@@ -0,0 +1,99 @@
+package sse
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	m := NewMemoryStore(3)
+	for _, e := range []Event{{Data: "a"}, {ID: "x", Data: "b"}, {Data: "c"}, {Data: "d"}} {
+		if _, err := m.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for lastID, want := range map[string][]Event{
+		"x": {{ID: "3", Data: "c"}, {ID: "4", Data: "d"}},
+		"4": {},
+		"1": {{ID: "x", Data: "b"}, {ID: "3", Data: "c"}, {ID: "4", Data: "d"}}, // expired
+	} {
+		events, err := m.Since(lastID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(events, want) {
+			t.Errorf("since %q: %+v, want %+v", lastID, events, want)
+		}
+	}
+}
+
+func TestEventStoreReplay(t *testing.T) {
+	s := New(WithEventStore(NewMemoryStore(10)))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	go func() {
+		s.Send(Event{Data: "stored", QoS: QoSStore})
+		s.Send(Event{Data: "noise"})
+		s.Send(Event{Data: "again", QoS: QoSStore})
+	}()
+	for _, want := range []string{"data: stored\nid: 1\n", "data: noise\n", "data: again\nid: 2\n"} {
+		if msg := readMessage(t, r); msg != want {
+			t.Errorf("live %q, want %q", msg, want)
+		}
+	}
+
+	r = connect(t, ts, http.Header{"Last-Event-ID": {"1"}})
+	if msg := readMessage(t, r); msg != "data: again\nid: 2\n" {
+		t.Errorf("replay %q", msg)
+	}
+}
+
+func TestSinceReplay(t *testing.T) {
+	store := NewMemoryStore(10)
+	s := New(WithEventStore(store))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	s.Send(Event{Data: "old", QoS: QoSStore})
+	store.events[0].stored = time.Now().Add(-time.Hour)
+	s.Send(Event{Data: "recent", QoS: QoSStore})
+
+	for _, query := range []string{"?since=5m", "?since=" + time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)} {
+		req, _ := http.NewRequest("GET", ts.URL+query, nil)
+		req.Header.Set("Accept", "text/event-stream")
+		res, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg := readMessage(t, bufio.NewReader(res.Body)); msg != "data: recent\nid: 2\n" {
+			t.Errorf("%s: replay %q", query, msg)
+		}
+		res.Body.Close()
+	}
+}
+
+func TestSince(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 10, 0, 0, time.UTC)
+	for value, want := range map[string]time.Time{
+		"5m":                   now.Add(-5 * time.Minute),
+		"2024-01-02T15:04:05Z": time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+	} {
+		if got, ok := since(value, now); !ok || !got.Equal(want) {
+			t.Errorf("%q: %v, want %v", value, got, want)
+		}
+	}
+	for _, value := range []string{"", "yesterday"} {
+		if _, ok := since(value, now); ok {
+			t.Errorf("%q is parsed", value)
+		}
+	}
+}
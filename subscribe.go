@@ -0,0 +1,50 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Subscribe connects to the stream at the given URL and returns the channel of
+// the data of the events with the given name decoded from JSON, without the
+// decoding in every consumer:
+//
+//	orders, err := sse.Subscribe[Order](ctx, url, "order")
+//
+// The empty name or "message" match the events without the name. The events
+// with the data not decoded into T are skipped. The channel is closed when the
+// context is done or the stream can not be continued.
+func Subscribe[T any](ctx context.Context, url, eventName string, opts ...ClientOption) (<-chan T, error) {
+	events, err := NewClient(url, opts...).Events(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if eventName == "message" {
+		eventName = ""
+	}
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for e := range events {
+			name := e.Name
+			if name == "message" {
+				name = ""
+			}
+			if name != eventName {
+				continue
+			}
+			var v T
+			if err := json.Unmarshal([]byte(e.Data), &v); err != nil {
+				continue
+			}
+			select {
+			case out <- v:
+			case <-ctx.Done():
+				for range events {
+				}
+				return
+			}
+		}
+	}()
+	return out, nil
+}
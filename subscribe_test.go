@@ -0,0 +1,37 @@
+package sse
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSubscribe(t *testing.T) {
+	s := New()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	type order struct {
+		ID    int     `json:"id"`
+		Total float64 `json:"total"`
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	orders, err := Subscribe[order](ctx, ts.URL, "order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitConnected(t, s, 1)
+	go func() {
+		s.Send(Event{Name: "user", Data: `{"id":1}`})
+		s.Send(Event{Name: "order", Data: `not json`})
+		s.Send(Event{Name: "order", Data: `{"id":7,"total":9.5}`})
+	}()
+	if o := <-orders; o != (order{ID: 7, Total: 9.5}) {
+		t.Errorf("order %+v", o)
+	}
+	cancel()
+	for range orders {
+	}
+}
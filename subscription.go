@@ -0,0 +1,146 @@
+package sse
+
+import "net/http"
+
+// SubscriptionHandler returns the handler changing the subscriptions of the
+// connected client without reconnecting, which would lose the events in
+// flight and reset the backoff of the browser. The client identified like the
+// stream (see WithClientID) posts the form values, applied to all its
+// connections:
+//
+//   - "subscribe" and "unsubscribe" add and remove the channels or the
+//     patterns (see WithChannels);
+//   - "filter" and "unfilter" add and remove the names of the events the
+//     client receives, "message" for the events without the name. Without the
+//     names the client receives the events of all names.
+//
+// The allow function authorizes the subscription of the request to the
+// channel; with the nil function no channel is subscribed to, so the clients
+// can only narrow the channels granted on connect. The changed channels are
+// the ClientInfo.Channels of the connections seen by the middleware and the
+// hooks. The handler responds with 204 No Content, 403 Forbidden if a channel
+// is not allowed and 404 Not Found if the client is not connected.
+//
+//	fetch("/events/subscriptions", {method: "POST", body: new URLSearchParams({subscribe: "orders.*"})})
+func (s *Server) SubscriptionHandler(allow func(r *http.Request, channel string) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.ParseForm(); err != nil || s.clientID == nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		id := s.clientID(r)
+		if id == "" {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		for _, channel := range r.PostForm["subscribe"] {
+			if allow == nil || !allow(r, channel) {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+		}
+
+		var found bool
+		s.mu.Lock()
+		for c := range s.clients {
+			if c.info.ID == id {
+				found = true
+				c.subscribe(r.PostForm["subscribe"], r.PostForm["unsubscribe"])
+				c.filterNames(r.PostForm["filter"], r.PostForm["unfilter"])
+			}
+		}
+		s.mu.Unlock()
+		if !found {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// subscribe changes the channels of the client. It must be called with the
+// server lock held.
+func (c *client) subscribe(add, remove []string) {
+	if len(add) == 0 && len(remove) == 0 {
+		return
+	}
+	// the new slice: the info given out before keeps the old channels
+	channels := update(append([]string(nil), c.info.Channels...), add, remove)
+	c.info.Channels = channels
+	c.channels = newChannelTrie(channels)
+}
+
+// current returns the client info with the subscriptions changed by the
+// SubscriptionHandler, read with the lock of the server owning the client.
+func (s *Server) current(c *client) ClientInfo {
+	owner := c.server.Load()
+	if owner == nil {
+		owner = s
+	}
+	owner.mu.RLock()
+	defer owner.mu.RUnlock()
+	return c.info
+}
+
+// filterNames changes the event names received by the client. It must be
+// called with the server lock held.
+func (c *client) filterNames(add, remove []string) {
+	if len(add) == 0 && len(remove) == 0 {
+		return
+	}
+	var names []string
+	for name := range c.names {
+		names = append(names, name)
+	}
+	names = update(names, add, remove)
+	c.names = nil
+	if len(names) > 0 {
+		c.names = make(map[string]bool, len(names))
+		for _, name := range names {
+			c.names[name] = true
+		}
+	}
+}
+
+// accepts reports whether the client receives the events of the name.
+func (c *client) accepts(name string) bool {
+	if c.names == nil {
+		return true
+	}
+	if name == "" {
+		name = "message"
+	}
+	return c.names[name]
+}
+
+// update returns the list without the removed values and with the added ones
+// not in the list yet.
+func update(list, add, remove []string) []string {
+	out := list[:0]
+	for _, v := range list {
+		if !contains(remove, v) {
+			out = append(out, v)
+		}
+	}
+	for _, v := range add {
+		if !contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// contains reports whether the list contains the value.
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
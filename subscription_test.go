@@ -0,0 +1,98 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubscriptionHandler(t *testing.T) {
+	s := New(
+		WithClientID(func(r *http.Request) string { return r.Header.Get("X-User") }),
+		WithChannels(func(r *http.Request) []string { return []string{"news"} }),
+	)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+	h := s.SubscriptionHandler(func(r *http.Request, channel string) bool {
+		return channel != "secret"
+	})
+	post := func(user string, form url.Values) int {
+		req := httptest.NewRequest("POST", "/subscriptions", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	r := connect(t, ts, http.Header{"X-User": {"alice"}})
+	waitConnected(t, s, 1)
+
+	if code := post("alice", url.Values{"subscribe": {"orders.*"}}); code != http.StatusNoContent {
+		t.Fatalf("subscribe status %d", code)
+	}
+	s.Send(Event{Data: "deep", Channel: "orders.new.eu"})
+	s.Send(Event{Data: "new", Channel: "orders.new"})
+	if msg := readMessage(t, r); msg != "data: new\n" {
+		t.Errorf("subscribed channel %q", msg)
+	}
+	var seen ClientInfo // info of the middleware
+	s.Use(func(e Event, info ClientInfo) (Event, bool) {
+		seen = info
+		return e, true
+	})
+	s.Send(Event{Name: "seen", Data: "x"})
+	readMessage(t, r)
+	if !reflect.DeepEqual(seen.Channels, []string{"news", "orders.*"}) || !seen.Subscribed("orders.eu") {
+		t.Errorf("middleware channels %q", seen.Channels)
+	}
+
+	if code := post("alice", url.Values{"filter": {"order"}}); code != http.StatusNoContent {
+		t.Fatalf("filter status %d", code)
+	}
+	s.Send(Event{Name: "ping", Data: "1"})
+	s.Send(Event{Name: "order", Data: "2", Channel: "news"})
+	if msg := readMessage(t, r); msg != "event: order\ndata: 2\n" {
+		t.Errorf("filtered event %q", msg)
+	}
+
+	form := url.Values{"unsubscribe": {"orders.*", "news"}, "unfilter": {"order"}}
+	if code := post("alice", form); code != http.StatusNoContent {
+		t.Fatalf("unsubscribe status %d", code)
+	}
+	s.Send(Event{Data: "old", Channel: "news"})
+	s.Send(Event{Name: "ping", Data: "all"})
+	if msg := readMessage(t, r); msg != "event: ping\ndata: all\n" {
+		t.Errorf("unfiltered event %q", msg)
+	}
+
+	for _, test := range []struct {
+		user string
+		form url.Values
+		code int
+	}{
+		{"alice", url.Values{"subscribe": {"secret"}}, http.StatusForbidden},
+		{"bob", url.Values{"subscribe": {"news"}}, http.StatusNotFound},
+		{"", url.Values{"subscribe": {"news"}}, http.StatusBadRequest},
+	} {
+		if code := post(test.user, test.form); code != test.code {
+			t.Errorf("%s %v: status %d, want %d", test.user, test.form, code, test.code)
+		}
+	}
+	h = s.SubscriptionHandler(nil) // narrowing only
+	if code := post("alice", url.Values{"subscribe": {"news"}}); code != http.StatusForbidden {
+		t.Errorf("not authorized subscription status %d", code)
+	}
+	if code := post("alice", url.Values{"unsubscribe": {"news"}}); code != http.StatusNoContent {
+		t.Errorf("not authorized unsubscription status %d", code)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/subscriptions", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status %d", w.Code)
+	}
+}
@@ -0,0 +1,49 @@
+package sse
+
+import (
+	"io"
+	"strings"
+)
+
+// Template is the text/template or html/template template.
+type Template interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// ClientTemplateData is the data of the template rendered for each client by
+// SendClientTemplate.
+type ClientTemplateData struct {
+	Client ClientInfo  // recipient of the event
+	Data   interface{} // data passed to SendClientTemplate
+}
+
+// SendTemplate renders the template with the data once and sends the result
+// as the event with the given name to all connected clients. It is handy for
+// HTML-over-the-wire applications, e.g. with HTMX.
+func (s *Server) SendTemplate(name string, tmpl Template, data interface{}) error {
+	text, err := execute(tmpl, data)
+	if err != nil {
+		return err
+	}
+	s.Send(Event{Name: name, Data: text})
+	return nil
+}
+
+// SendClientTemplate renders the template for each client with the
+// ClientTemplateData and sends the result as the event with the given name.
+// It returns the first render error; the clients whose template failed to
+// render do not receive the event.
+func (s *Server) SendClientTemplate(name string, tmpl Template, data interface{}) error {
+	return s.SendLazy(name, func(info ClientInfo) (string, error) {
+		return execute(tmpl, ClientTemplateData{Client: info, Data: data})
+	})
+}
+
+// execute returns the rendered template.
+func execute(tmpl Template, data interface{}) (string, error) {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
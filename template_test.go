@@ -0,0 +1,36 @@
+package sse
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendTemplate(t *testing.T) {
+	s := New(WithClientID(func(r *http.Request) string { return r.Header.Get("X-User") }))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, http.Header{"X-User": {"alice"}})
+	waitConnected(t, s, 1)
+
+	row := template.Must(template.New("row").Parse("<li>{{.}}</li>"))
+	greeting := template.Must(template.New("greeting").Parse("<p>{{.Data}}, {{.Client.ID}}</p>"))
+	go func() {
+		_ = s.SendTemplate("row", row, "<b>")
+		_ = s.SendClientTemplate("greeting", greeting, "Hello")
+	}()
+	if msg := readMessage(t, r); msg != "event: row\ndata: <li>&lt;b&gt;</li>\n" {
+		t.Errorf("row %q", msg)
+	}
+	if msg := readMessage(t, r); msg != "event: greeting\ndata: <p>Hello, alice</p>\n" {
+		t.Errorf("greeting %q", msg)
+	}
+
+	broken := template.Must(template.New("broken").Parse("{{.Missing}}"))
+	if err := s.SendTemplate("broken", broken, 1); err == nil {
+		t.Error("template error is not returned")
+	}
+}
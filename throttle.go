@@ -0,0 +1,82 @@
+package sse
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WithThrottleParam lets the clients opt into the slower stream with the query
+// parameter of the given name, such as "throttle" for ?throttle=1s: the events
+// are held and written to the connection at most once per interval, coalesced
+// into one write. The intervals longer than max are reduced to it.
+func WithThrottleParam(name string, max time.Duration) Option {
+	return func(s *Server) {
+		s.throttleParam = name
+		s.throttleMax = max
+	}
+}
+
+// throttle returns the write throttle of the connection or nil. The throttle
+// query parameter takes precedence over the throttle of the client class.
+func (s *Server) throttle(r *http.Request, info ClientInfo, clock Clock) *throttle {
+	d := s.class(info).Throttle
+	if s.throttleParam != "" {
+		if param, err := time.ParseDuration(r.URL.Query().Get(s.throttleParam)); err == nil && param > 0 {
+			d = param
+		}
+	}
+	if d <= 0 {
+		return nil
+	}
+	if s.throttleMax > 0 && d > s.throttleMax {
+		d = s.throttleMax
+	}
+	return &throttle{interval: d, clock: clock}
+}
+
+// throttle holds the events of the connection and writes them at most once
+// per interval.
+type throttle struct {
+	interval time.Duration
+	clock    Clock            // timer source
+	last     time.Time        // time of the last write
+	batch    strings.Builder  // events held until the interval is due
+	timer    Timer            // delayed write
+	C        <-chan time.Time // fires when the delayed write is due
+}
+
+// hold adds the encoded event to the batch. It returns the batch and true if
+// the interval is due and the batch is written now; else the delayed write is
+// scheduled. Without the throttle the event is written at once.
+func (t *throttle) hold(data string, now time.Time) (string, bool) {
+	if t == nil {
+		return data + "\n", true
+	}
+	t.batch.WriteString(data)
+	t.batch.WriteString("\n")
+	if wait := t.interval - now.Sub(t.last); wait > 0 {
+		if t.C == nil {
+			t.timer = t.clock.NewTimer(wait)
+			t.C = t.timer.C()
+		}
+		return "", false
+	}
+	return t.release(now), true
+}
+
+// release returns the batch written now and resets it.
+func (t *throttle) release(now time.Time) string {
+	batch := t.batch.String()
+	t.batch.Reset()
+	t.last = now
+	t.C = nil
+	return batch
+}
+
+// stop stops the delayed write.
+func (t *throttle) stop() {
+	if t != nil && t.timer != nil {
+		t.timer.Stop()
+	}
+}
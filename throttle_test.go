@@ -0,0 +1,115 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestThrottleParam(t *testing.T) {
+	s := New(WithThrottleParam("throttle", time.Second))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	req, _ := http.NewRequest("GET", ts.URL+"?throttle=50ms", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	r := bufio.NewReader(res.Body)
+	waitConnected(t, s, 1)
+
+	go s.Send(Event{Data: "a"})
+	if msg := readMessage(t, r); msg != "data: a\n" {
+		t.Errorf("first %q", msg)
+	}
+	start := time.Now()
+	go func() {
+		s.Send(Event{Data: "b"})
+		s.Send(Event{Data: "c"})
+	}()
+	for _, want := range []string{"data: b\n", "data: c\n"} {
+		if msg := readMessage(t, r); msg != want {
+			t.Errorf("got %q, want %q", msg, want)
+		}
+	}
+	if d := time.Since(start); d < 30*time.Millisecond {
+		t.Errorf("throttled events delivered in %v", d)
+	}
+}
+
+func TestThrottleMax(t *testing.T) {
+	s := New(WithThrottleParam("throttle", time.Second))
+	for query, want := range map[string]time.Duration{
+		"":              0,
+		"?throttle=bad": 0,
+		"?throttle=2s":  time.Second,
+		"?throttle=5ms": 5 * time.Millisecond,
+	} {
+		var got time.Duration
+		if th := s.throttle(httptest.NewRequest("GET", "/"+query, nil), ClientInfo{}, systemClock{}); th != nil {
+			got = th.interval
+		}
+		if got != want {
+			t.Errorf("%q: throttle %v, want %v", query, got, want)
+		}
+	}
+}
+
+// writesRecorder records the writes of the response.
+type writesRecorder struct {
+	header http.Header
+	mu     sync.Mutex
+	writes []string
+}
+
+func (w *writesRecorder) Header() http.Header { return w.header }
+func (w *writesRecorder) WriteHeader(int)     {}
+func (w *writesRecorder) Flush()              {}
+
+func (w *writesRecorder) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func (w *writesRecorder) recorded() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.writes...)
+}
+
+func TestThrottleBatch(t *testing.T) {
+	s := New(WithThrottleParam("throttle", time.Second))
+	defer s.Close()
+	w := &writesRecorder{header: make(http.Header)}
+	req := httptest.NewRequest("GET", "/?throttle=50ms", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	ctx, cancel := context.WithCancel(req.Context())
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		s.ServeHTTP(w, req.WithContext(ctx))
+	}()
+	waitConnected(t, s, 1)
+
+	s.Send(Event{Data: "a"})
+	s.Send(Event{Data: "b"})
+	s.Send(Event{Data: "c"})
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-served
+	want := []string{"data: a\n\n", "data: b\n\ndata: c\n\n"}
+	if writes := w.recorded(); !reflect.DeepEqual(writes, want) {
+		t.Errorf("writes %q, want %q", writes, want)
+	}
+}
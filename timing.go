@@ -0,0 +1,48 @@
+package sse
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithServerTiming enables the streaming metrics for the frontend performance
+// tooling. The Server-Timing header of the response carries the time spent to
+// set up the connection (authorization, replay), and every interval the
+// comment line carries the average queue latency and write time of the events
+// sent since the previous report, in milliseconds:
+//
+//	: server-timing queue;dur=0.215, write;dur=0.040
+//
+// Nothing is reported for the intervals without events.
+func WithServerTiming(interval time.Duration) Option {
+	return func(s *Server) {
+		s.timing = interval
+	}
+}
+
+// timings accumulates the streaming metrics of the client.
+type timings struct {
+	queue, write time.Duration
+	n            int
+}
+
+// add adds the metrics of the sent event.
+func (t *timings) add(queue, write time.Duration) {
+	t.queue += queue
+	t.write += write
+	t.n++
+}
+
+// report returns the comment with the average metrics and resets them.
+func (t *timings) report() string {
+	n := time.Duration(t.n)
+	report := fmt.Sprintf(": server-timing queue;dur=%.3f, write;dur=%.3f\n",
+		ms(t.queue/n), ms(t.write/n))
+	*t = timings{}
+	return report
+}
+
+// ms returns the duration in milliseconds.
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
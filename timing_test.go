@@ -0,0 +1,54 @@
+package sse
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerTiming(t *testing.T) {
+	s := New(WithServerTiming(20 * time.Millisecond))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	req, err := http.NewRequest("GET", ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if timing := res.Header.Get("Server-Timing"); !strings.HasPrefix(timing, "setup;dur=") {
+		t.Errorf("server timing %q", timing)
+	}
+	waitConnected(t, s, 1)
+
+	r := bufio.NewReader(res.Body)
+	go s.Send(Event{Data: "hello"})
+	if msg := readMessage(t, r); msg != "data: hello\n" {
+		t.Errorf("message %q", msg)
+	}
+	msg := readMessage(t, r)
+	if !strings.HasPrefix(msg, ": server-timing queue;dur=") || !strings.Contains(msg, ", write;dur=") {
+		t.Errorf("report %q", msg)
+	}
+}
+
+func TestTimingsReport(t *testing.T) {
+	var tm timings
+	tm.add(time.Millisecond, 2*time.Millisecond)
+	tm.add(3*time.Millisecond, 0)
+	if report := tm.report(); report != ": server-timing queue;dur=2.000, write;dur=1.000\n" {
+		t.Errorf("report %q", report)
+	}
+	if tm.n != 0 {
+		t.Error("not reset")
+	}
+}
@@ -0,0 +1,89 @@
+package sse
+
+import "context"
+
+// traceKey is the context key of the W3C traceparent.
+type traceKey struct{}
+
+// ContextWithTraceParent returns the context carrying the W3C traceparent,
+// the default trace context of the published and the consumed events when
+// neither WithTraceContext nor WithClientTraceContext is set.
+func ContextWithTraceParent(ctx context.Context, traceparent string) context.Context {
+	return context.WithValue(ctx, traceKey{}, traceparent)
+}
+
+// TraceParent returns the W3C traceparent carried by the context or an empty
+// string.
+func TraceParent(ctx context.Context) string {
+	traceparent, _ := ctx.Value(traceKey{}).(string)
+	return traceparent
+}
+
+// WithTraceContext sets the function returning the trace context of the
+// publishing context, such as the traceparent injected by the OpenTelemetry
+// propagator, so the events published with Traced continue the trace of the
+// publisher in the consumers:
+//
+//	sse.WithTraceContext(func(ctx context.Context) string {
+//		carrier := propagation.MapCarrier{}
+//		otel.GetTextMapPropagator().Inject(ctx, carrier)
+//		return carrier.Get("traceparent")
+//	})
+func WithTraceContext(fn func(ctx context.Context) string) Option {
+	return func(s *Server) {
+		s.traceContext = fn
+	}
+}
+
+// WithTrace sets the trace context of the event.
+func WithTrace(trace string) EventOption {
+	return func(e *Event) {
+		e.Trace = trace
+	}
+}
+
+// Traced returns the event with the trace context of the publishing context,
+// unless the event has one already:
+//
+//	s.Send(s.Traced(r.Context(), sse.Event{Name: "order", Data: data}))
+//
+// The trace context is sent in the trace field of the event, or in the
+// envelope metadata with WithEnvelope; the browsers ignore it.
+func (s *Server) Traced(ctx context.Context, e Event) Event {
+	if e.Trace != "" {
+		return e
+	}
+	if s.traceContext != nil {
+		e.Trace = s.traceContext(ctx)
+	} else {
+		e.Trace = TraceParent(ctx)
+	}
+	return e
+}
+
+// WithClientTraceContext sets the function restoring the trace context of the
+// received event into the consuming context (see Client.Context), such as
+// with the OpenTelemetry propagator:
+//
+//	sse.WithClientTraceContext(func(ctx context.Context, trace string) context.Context {
+//		carrier := propagation.MapCarrier{"traceparent": trace}
+//		return otel.GetTextMapPropagator().Extract(ctx, carrier)
+//	})
+func WithClientTraceContext(fn func(ctx context.Context, trace string) context.Context) ClientOption {
+	return func(c *Client) {
+		c.traceContext = fn
+	}
+}
+
+// Context returns the context of consuming the event with the trace context
+// of its publisher restored, or the context as is if the event is not
+// traced.
+func (c *Client) Context(ctx context.Context, e Event) context.Context {
+	if e.Trace == "" {
+		return ctx
+	}
+	if c.traceContext != nil {
+		return c.traceContext(ctx, e.Trace)
+	}
+	return ContextWithTraceParent(ctx, e.Trace)
+}
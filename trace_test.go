@@ -0,0 +1,73 @@
+package sse
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestTraced(t *testing.T) {
+	ctx := ContextWithTraceParent(context.Background(), traceparent)
+	s := New()
+	if e := s.Traced(ctx, Event{Data: "x"}); e.Trace != traceparent {
+		t.Errorf("trace %q", e.Trace)
+	}
+	if e := s.Traced(ctx, Event{Data: "x", Trace: "own"}); e.Trace != "own" {
+		t.Errorf("own trace replaced with %q", e.Trace)
+	}
+	s = New(WithTraceContext(func(ctx context.Context) string { return "custom" }))
+	if e := s.Traced(ctx, Event{Data: "x"}); e.Trace != "custom" {
+		t.Errorf("custom trace %q", e.Trace)
+	}
+	if e := NewEventData("x", WithTrace(traceparent)); e.Trace != traceparent {
+		t.Errorf("option trace %q", e.Trace)
+	}
+	if data := s.encode(Event{Data: "x", Trace: traceparent}); data != "data: x\ntrace: "+traceparent+"\n" {
+		t.Errorf("encoded %q", data)
+	}
+}
+
+func TestClientTrace(t *testing.T) {
+	for _, envelope := range []bool{false, true} {
+		var opts []Option
+		if envelope {
+			opts = append(opts, WithEnvelope())
+		}
+		s := New(opts...)
+		ts := httptest.NewServer(s)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		c := NewClient(ts.URL, WithClientEnvelope())
+		events, err := c.Events(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		waitConnected(t, s, 1)
+		published := ContextWithTraceParent(context.Background(), traceparent)
+		go s.Send(s.Traced(published, Event{Data: "x"}))
+		e := <-events
+		if e != (Event{Data: "x", Trace: traceparent}) {
+			t.Errorf("envelope %v, event %+v", envelope, e)
+		}
+		if trace := TraceParent(c.Context(context.Background(), e)); trace != traceparent {
+			t.Errorf("envelope %v, restored trace %q", envelope, trace)
+		}
+		cancel()
+		s.Close()
+		ts.Close()
+	}
+
+	c := NewClient("http://localhost", WithClientTraceContext(func(ctx context.Context, trace string) context.Context {
+		return ContextWithTraceParent(ctx, strings.ToUpper(trace))
+	}))
+	ctx := context.Background()
+	if c.Context(ctx, Event{}) != ctx {
+		t.Error("context of the event without the trace is changed")
+	}
+	if trace := TraceParent(c.Context(ctx, Event{Trace: "t"})); trace != "T" {
+		t.Errorf("custom restored trace %q", trace)
+	}
+}
@@ -0,0 +1,50 @@
+package sse
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestClientRootCAs(t *testing.T) {
+	s := New()
+	ts := httptest.NewTLSServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if _, err := NewClient(ts.URL).Events(ctx); err == nil {
+		t.Error("connected with untrusted certificate")
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ts.Certificate())
+	if _, err := NewClient(ts.URL, WithRootCAs(pool)).Events(ctx); err != nil {
+		t.Fatal(err)
+	}
+	waitConnected(t, s, 1)
+}
+
+func TestClientProxy(t *testing.T) {
+	s := New()
+	proxied := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied <- r.URL.String()
+		s.ServeHTTP(w, r)
+	}))
+	defer proxy.Close()
+	defer s.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if _, err := NewClient("http://events.example/stream", WithProxy(proxyURL)).Events(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if u := <-proxied; u != "http://events.example/stream" {
+		t.Errorf("proxied %q", u)
+	}
+}
@@ -0,0 +1,30 @@
+package sse
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEventTTL(t *testing.T) {
+	s := New(WithRateLimit(10, 1))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	r := connect(t, ts, nil)
+	waitConnected(t, s, 1)
+	go func() {
+		s.Send(Event{Data: "first"})
+		s.Send(Event{Data: "quote", TTL: time.Millisecond}) // waits for the rate limit
+		s.Send(Event{Data: "last"})
+	}()
+	for _, want := range []string{"data: first\n", "data: last\n"} {
+		if msg := readMessage(t, r); msg != want {
+			t.Errorf("got %q, want %q", msg, want)
+		}
+	}
+	if n := s.Stats().Expired; n != 1 {
+		t.Errorf("expired %d", n)
+	}
+}
@@ -0,0 +1,54 @@
+package sse
+
+import "context"
+
+// WaitForClients blocks until at least n clients are connected or the context
+// is done. It is useful in tests and in the batch jobs starting to publish only
+// once a consumer is attached.
+func (s *Server) WaitForClients(ctx context.Context, n int) error {
+	return s.waitFor(ctx, func() bool { return len(s.clients) >= n })
+}
+
+// SendWait sends the event like Send, but first waits until at least one
+// connected client is allowed to receive it, instead of discarding the event
+// into the void. It is useful for the job progress streams, where the browser
+// connects a moment after the job starts. It returns the context error if the
+// context is done before the subscriber connects.
+func (s *Server) SendWait(ctx context.Context, e Event) error {
+	err := s.waitFor(ctx, func() bool {
+		for c := range s.clients {
+			if e.allowed(c) {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return err
+	}
+	s.Send(e)
+	return nil
+}
+
+// waitFor blocks until the condition checked with the server locked is met on
+// the client joining or the context is done.
+func (s *Server) waitFor(ctx context.Context, cond func() bool) error {
+	for {
+		s.mu.Lock()
+		if cond() {
+			s.mu.Unlock()
+			return nil
+		}
+		if s.joined == nil {
+			s.joined = make(chan struct{})
+		}
+		joined := s.joined
+		s.mu.Unlock()
+
+		select {
+		case <-joined:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWaitForClients(t *testing.T) {
+	s := New()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.WaitForClients(ctx, 1); err != context.DeadlineExceeded {
+		t.Error("no clients:", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- s.WaitForClients(context.Background(), 2) }()
+	connect(t, ts, nil)
+	connect(t, ts, nil)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("clients are not awaited")
+	}
+	if err := s.WaitForClients(context.Background(), 1); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSendWait(t *testing.T) {
+	s := New(WithChannels(func(r *http.Request) []string {
+		return r.URL.Query()["job"]
+	}))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.SendWait(ctx, Event{Data: "lost"}); err != context.DeadlineExceeded {
+		t.Error("no clients:", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- s.SendWait(context.Background(), Event{Data: "50%", Channel: "42"}) }()
+	get := func(query string) *bufio.Reader {
+		t.Helper()
+		req, _ := http.NewRequest("GET", ts.URL+query, nil)
+		req.Header.Set("Accept", "text/event-stream")
+		res, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { res.Body.Close() })
+		return bufio.NewReader(res.Body)
+	}
+	get("?job=7")
+	waitConnected(t, s, 1)
+	select {
+	case err := <-done:
+		t.Fatal("sent to the other job:", err)
+	default:
+	}
+	if msg := readMessage(t, get("?job=42")); msg != "data: 50%\n" {
+		t.Errorf("message %q", msg)
+	}
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
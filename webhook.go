@@ -0,0 +1,120 @@
+package sse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Webhook is the Publisher posting the events to the URL of the server-to-
+// server consumer. Mirrored from the Server (see Mirror), it receives every
+// broadcast, or the ones accepted by the filter, with the same publish call
+// that feeds the browsers, and the failed posts are retried:
+//
+//	s.Mirror(ctx, []sse.Publisher{
+//		sse.NewWebhook("https://billing.example.com/hooks/orders", func(e sse.Event) bool {
+//			return e.Channel == "orders"
+//		}),
+//	})
+//
+// The event is posted as the JSON object:
+//
+//	{"id":"7","event":"order","data":"{\"id\":1}","channel":"orders"}
+//
+// with the traceparent header of the traced event (see Traced) and, if the key
+// is set, the X-SSE-Signature header with the event signature (see Sign). Any
+// 2xx status accepts the event; the client errors other than 408 and 429 are
+// not retried.
+type Webhook struct {
+	URL    string
+	Client *http.Client     // http.DefaultClient if nil
+	Filter func(Event) bool // events posted, all if nil
+	Key    []byte           // signature key, not signed if nil
+	Header http.Header      // additional request headers
+}
+
+var _ Publisher = (*Webhook)(nil)
+
+// NewWebhook returns the webhook posting the events accepted by the filter to
+// the URL. A nil filter accepts all events.
+func NewWebhook(url string, filter func(Event) bool) *Webhook {
+	return &Webhook{URL: url, Filter: filter}
+}
+
+// webhookEvent is the posted event.
+type webhookEvent struct {
+	ID      string `json:"id,omitempty"`
+	Event   string `json:"event,omitempty"`
+	Data    string `json:"data"`
+	Channel string `json:"channel,omitempty"`
+}
+
+// SendContext posts the event accepted by the filter. It returns the
+// ErrBadStatus if the consumer does not accept the event.
+func (w *Webhook) SendContext(ctx context.Context, e Event) error {
+	if w.Filter != nil && !w.Filter(e) {
+		return nil
+	}
+	body, err := json.Marshal(webhookEvent{ID: e.ID, Event: e.Name, Data: e.Data, Channel: e.Channel})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range w.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.Trace != "" {
+		req.Header.Set("traceparent", e.Trace)
+	}
+	if w.Key != nil {
+		req.Header.Set("X-SSE-Signature", Sign(w.Key, e))
+	}
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode/100 != 2 {
+		return &ErrBadStatus{Code: res.StatusCode, Body: excerpt(res)}
+	}
+	_, _ = io.Copy(io.Discard, res.Body) // the connection is reused
+	return res.Body.Close()
+}
+
+// Send posts the event, ignoring the errors.
+func (w *Webhook) Send(e Event) {
+	_ = w.SendContext(context.Background(), e)
+}
+
+// Publish posts the event with the given data and options.
+func (w *Webhook) Publish(data string, opts ...EventOption) error {
+	return w.SendContext(context.Background(), NewEventData(data, opts...))
+}
+
+// Event posts the event with the data encoded as JSON.
+func (w *Webhook) Event(id, name string, v interface{}) error {
+	e, err := NewEvent(id, name, v)
+	if err != nil {
+		return err
+	}
+	return w.SendContext(context.Background(), e)
+}
+
+// Comment does nothing: the comments are not posted.
+func (w *Webhook) Comment(text string) {}
+
+// Retry does nothing: the reconnection delay is not posted.
+func (w *Webhook) Retry(d time.Duration) {}
+
+// Close does nothing.
+func (w *Webhook) Close() {}
@@ -0,0 +1,79 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhook(t *testing.T) {
+	key := []byte("key")
+	var calls atomic.Int32
+	posted := make(chan webhookEvent, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			http.Error(w, "busy", http.StatusServiceUnavailable) // retried
+			return
+		}
+		var e webhookEvent
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Error(err)
+		}
+		if r.Header.Get("traceparent") != traceparent ||
+			!Verify(key, Event{ID: e.ID, Name: e.Event, Data: e.Data, Channel: e.Channel, Trace: traceparent},
+				r.Header.Get("X-SSE-Signature")) {
+			t.Errorf("headers %v", r.Header)
+		}
+		posted <- e
+	}))
+	defer hook.Close()
+
+	s := New()
+	defer s.Close()
+	webhook := NewWebhook(hook.URL, func(e Event) bool { return e.Channel == "orders" })
+	webhook.Key = key
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Mirror(ctx, []Publisher{webhook})
+
+	s.Send(Event{Data: "skipped"})
+	s.Send(s.Traced(ContextWithTraceParent(ctx, traceparent),
+		Event{ID: "7", Name: "order", Data: `{"id":1}`, Channel: "orders"}))
+	select {
+	case e := <-posted:
+		if e != (webhookEvent{ID: "7", Event: "order", Data: `{"id":1}`, Channel: "orders"}) {
+			t.Errorf("posted %+v", e)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("event is not posted")
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("%d calls", n)
+	}
+}
+
+func TestWebhookRejected(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad event", http.StatusBadRequest)
+	}))
+	defer hook.Close()
+
+	err := NewWebhook(hook.URL, nil).Publish("x")
+	var status *ErrBadStatus
+	if !errors.As(err, &status) || status.Code != http.StatusBadRequest || status.Body != "bad event\n" {
+		t.Errorf("error %v", err)
+	}
+	if retryable(err) {
+		t.Error("rejected event is retried")
+	}
+	for _, err := range []error{&ErrBadStatus{Code: http.StatusTooManyRequests}, errors.New("reset")} {
+		if !retryable(err) {
+			t.Errorf("%v is not retried", err)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package sse
+
+import "net/http"
+
+// Wrap adds the HTTP middleware called for every stream request before the
+// response headers are written, in the order they are added, so the usual
+// authentication, rate limiting and tracing middleware may reject the request
+// with the normal HTTP response instead of the stream, or pass it on with the
+// values added to its context:
+//
+//	s.Wrap(auth.Middleware, ratelimit.PerIP(10), otelhttp.NewMiddleware("events"))
+//
+// The middleware applies to all mounts of the server, including the CORS
+// preflight requests. It should be called before the server starts serving
+// the clients.
+func (s *Server) Wrap(mw ...func(http.Handler) http.Handler) {
+	s.wrappers = append(s.wrappers, mw...)
+}
+
+// serve serves the events stream with the given mount options through the
+// HTTP middleware chain.
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, h *handler) {
+	if len(s.wrappers) == 0 {
+		s.serveStream(w, r, h)
+		return
+	}
+	var next http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.serveStream(w, r, h)
+	})
+	for i := len(s.wrappers) - 1; i >= 0; i-- {
+		next = s.wrappers[i](next)
+	}
+	next.ServeHTTP(w, r)
+}
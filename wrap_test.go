@@ -0,0 +1,60 @@
+package sse
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type userKey struct{}
+
+func TestWrap(t *testing.T) {
+	s := New(WithClientID(func(r *http.Request) string {
+		user, _ := r.Context().Value(userKey{}).(string)
+		return user
+	}))
+	var order []string
+	s.Wrap(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			order = append(order, "auth")
+			user := r.Header.Get("X-User")
+			if user == "" {
+				http.Error(w, "no user", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+		})
+	}, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			order = append(order, "trace")
+			w.Header().Set("X-Trace", "1")
+			next.ServeHTTP(w, r)
+		})
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+	defer s.Close()
+
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status %s", res.Status)
+	}
+
+	r := connect(t, ts, http.Header{"X-User": {"alice"}})
+	waitConnected(t, s, 1)
+	if len(order) != 3 || order[1] != "auth" || order[2] != "trace" {
+		t.Errorf("middleware order %v", order)
+	}
+	// the identity is taken from the context set by the middleware
+	go s.EventTo("alice", "", "", "hi")
+	if msg := readMessage(t, r); msg != "data: hi\n" {
+		t.Errorf("event %q", msg)
+	}
+}
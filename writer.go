@@ -0,0 +1,65 @@
+package sse
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// errNotSupported is returned by the stream writer when the response writer
+// does not support the feature.
+var errNotSupported = errors.New("sse: feature not supported")
+
+// streamWriter is the response writer of the stream, hiding the way the
+// response is flushed and its deadline is set. It works with the response
+// writers wrapped by the middleware (Unwrap method) and the ones that do not
+// implement http.Flusher, such as some HTTP/3 servers.
+type streamWriter struct {
+	http.ResponseWriter
+	flush    func() error
+	deadline func(time.Time) error
+}
+
+// newStreamWriter returns the stream writer for the response writer. If any
+// writer in the chain supports flushing or the deadline, it is used; else the
+// stream degrades to the buffered writes.
+func newStreamWriter(w http.ResponseWriter) *streamWriter {
+	sw := &streamWriter{ResponseWriter: w}
+	for rw := w; rw != nil; {
+		if sw.flush == nil {
+			switch f := rw.(type) {
+			case interface{ FlushError() error }:
+				sw.flush = f.FlushError
+			case http.Flusher:
+				sw.flush = func() error { f.Flush(); return nil }
+			}
+		}
+		if sw.deadline == nil {
+			if d, ok := rw.(interface{ SetWriteDeadline(time.Time) error }); ok {
+				sw.deadline = d.SetWriteDeadline
+			}
+		}
+		u, ok := rw.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		rw = u.Unwrap()
+	}
+	return sw
+}
+
+// Flush sends the buffered data to the client, if supported.
+func (w *streamWriter) Flush() error {
+	if w.flush == nil {
+		return nil
+	}
+	return w.flush()
+}
+
+// SetWriteDeadline sets the deadline of the writes, if supported.
+func (w *streamWriter) SetWriteDeadline(t time.Time) error {
+	if w.deadline == nil {
+		return errNotSupported
+	}
+	return w.deadline(t)
+}
@@ -0,0 +1,63 @@
+package sse
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// plainWriter hides the optional interfaces of the response writer.
+type plainWriter struct {
+	http.ResponseWriter
+}
+
+// wrappedWriter is the response writer of the middleware.
+type wrappedWriter struct {
+	http.ResponseWriter
+}
+
+func (w wrappedWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+func TestStreamWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	sw := newStreamWriter(wrappedWriter{rec})
+	sw.Write([]byte("data"))
+	if err := sw.Flush(); err != nil || !rec.Flushed {
+		t.Error("not flushed through the wrapper:", err)
+	}
+	if err := sw.SetWriteDeadline(time.Now()); err != errNotSupported {
+		t.Error("deadline:", err)
+	}
+
+	rec = httptest.NewRecorder()
+	sw = newStreamWriter(plainWriter{rec})
+	if err := sw.Flush(); err != nil || rec.Flushed {
+		t.Error("flushed the plain writer:", err)
+	}
+}
+
+func TestServeWithoutFlusher(t *testing.T) {
+	s := New(WithOnConnectEvents(func(*http.Request, string) []Event {
+		return []Event{{Data: "hello"}}
+	}))
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	r.Header.Set("Accept", "text/event-stream")
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.ServeHTTP(plainWriter{rec}, r)
+	}()
+	waitConnected(t, s, 1)
+	cancel()
+	<-done
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "data: hello\n") {
+		t.Errorf("response %d %q", rec.Code, rec.Body)
+	}
+}